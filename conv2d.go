@@ -0,0 +1,539 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+)
+
+/*
+	This file used to be backed by the im2colOp/col2imOp pair in op_nn.go.
+	That pair never actually worked: InferShape referenced fields im2colOp
+	didn't have, Do() referenced an undeclared im, and the f64s kernels mixed
+	up which variable was the channel count and which was the stride
+	(StrideW/stridew/retChans*chanWidths, to name a few). Conv2DOp replaces
+	both with a single op that, for each batch item and group, does one
+	im2col into a reused scratch buffer followed by one GEMM via
+	tensor.MatMul, which is both correct and a fair bit faster than the naive
+	loop the old pair was going for.
+*/
+
+// ConvLayout says which axis of a convolution's input/output tensors holds
+// the channel dimension.
+type ConvLayout byte
+
+const (
+	// NCHW lays out tensors as (batch, channels, height, width). This is the
+	// default, matching torch.nn.Conv2d.
+	NCHW ConvLayout = iota
+	// NHWC lays out tensors as (batch, height, width, channels).
+	NHWC
+)
+
+func (l ConvLayout) String() string {
+	switch l {
+	case NCHW:
+		return "NCHW"
+	case NHWC:
+		return "NHWC"
+	default:
+		return fmt.Sprintf("ConvLayout(%d)", byte(l))
+	}
+}
+
+// Conv2DOp performs a 2D convolution of a (kh, kw) kernel over a batched
+// image tensor, via a single batched GEMM: for each item in the batch, im2col
+// gathers the (C/groups * kh * kw, oh * ow) patch matrix into a reused
+// buffer, then a K x (C/groups*kh*kw) @ (C/groups*kh*kw) x (oh*ow) matrix
+// multiplication (one per group) produces that item's K x (oh*ow) output.
+type Conv2DOp struct {
+	KernelH, KernelW     int
+	PadH, PadW           int
+	StrideH, StrideW     int
+	DilationH, DilationW int
+	Groups               int
+	Layout               ConvLayout
+
+	// buf is the [C/groups*kh*kw, oh*ow] im2col scratch space. It is grown
+	// (never shrunk) and reused across batch items within a single Do(), and
+	// across calls as long as the input shape doesn't change.
+	buf []float64
+}
+
+// Conv2DOpt configures optional Conv2DOp fields; unset options keep the
+// PyTorch-matching defaults (dilation 1, groups 1, NCHW).
+type Conv2DOpt func(*Conv2DOp)
+
+// WithDilation sets the convolution's per-axis dilation. The default is 1,1
+// (no dilation).
+func WithDilation(h, w int) Conv2DOpt {
+	return func(op *Conv2DOp) { op.DilationH, op.DilationW = h, w }
+}
+
+// WithGroups splits the input and output channels into groups independent
+// convolutions, as in torch.nn.Conv2d's groups parameter. The default is 1.
+func WithGroups(groups int) Conv2DOpt {
+	return func(op *Conv2DOp) { op.Groups = groups }
+}
+
+// WithConvLayout sets whether the input/output tensors are NCHW (the
+// default) or NHWC.
+func WithConvLayout(l ConvLayout) Conv2DOpt {
+	return func(op *Conv2DOp) { op.Layout = l }
+}
+
+// NewConv2DOp creates a Conv2DOp with a (kh, kw) kernel, (padH, padW)
+// zero-padding and (strideH, strideW) stride, defaulting to no dilation,
+// a single group and NCHW layout.
+func NewConv2DOp(kernelH, kernelW, padH, padW, strideH, strideW int, opts ...Conv2DOpt) *Conv2DOp {
+	op := &Conv2DOp{
+		KernelH: kernelH, KernelW: kernelW,
+		PadH: padH, PadW: padW,
+		StrideH: strideH, StrideW: strideW,
+		DilationH: 1, DilationW: 1,
+		Groups: 1,
+		Layout: NCHW,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+	return op
+}
+
+func (op *Conv2DOp) Arity() int { return 2 }
+
+// Conv2D :: (Floats a) ⇒ Tensor a → Tensor a → Tensor a
+func (op *Conv2DOp) Type() hm.Type {
+	t := hm.TypeVariable('a')
+	tt := newTensorType(4, t)
+	return hm.NewFnType(tt, tt, tt)
+}
+
+func (op *Conv2DOp) InferShape(shapes ...DimSizer) (retVal tensor.Shape, err error) {
+	if err = checkArity(op, len(shapes)); err != nil {
+		return
+	}
+
+	im, ok := shapes[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Conv2DOp.InferShape expected a tensor.Shape, got %T", shapes[0])
+	}
+	kernel, ok := shapes[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Conv2DOp.InferShape expected a tensor.Shape, got %T", shapes[1])
+	}
+
+	n, _, h, w := op.dims(im)
+	k := kernel[0]
+	oh := op.outDim(h, op.KernelH, op.DilationH, op.PadH, op.StrideH)
+	ow := op.outDim(w, op.KernelW, op.DilationW, op.PadW, op.StrideW)
+
+	if op.Layout == NHWC {
+		return tensor.Shape{n, oh, ow, k}, nil
+	}
+	return tensor.Shape{n, k, oh, ow}, nil
+}
+
+func (op *Conv2DOp) outDim(size, kernel, dilation, pad, stride int) int {
+	effectiveKernel := dilation*(kernel-1) + 1
+	return (size+2*pad-effectiveKernel)/stride + 1
+}
+
+// dims extracts (batch, channels, height, width) out of im, regardless of
+// op.Layout.
+func (op *Conv2DOp) dims(im tensor.Shape) (n, c, h, w int) {
+	if op.Layout == NHWC {
+		return im[0], im[3], im[1], im[2]
+	}
+	return im[0], im[1], im[2], im[3]
+}
+
+func (op *Conv2DOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+
+	im, kernel := inputs[0], inputs[1]
+	if im.Dtype() != kernel.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, im.Dtype(), kernel.Dtype())
+	}
+
+	retShape, err := op.InferShape(im.Shape(), kernel.Shape())
+	if err != nil {
+		return nil, err
+	}
+	prealloc := tensor.New(tensor.Of(im.Dtype()), tensor.WithShape(retShape...))
+	return op.UsePreallocDo(prealloc, im, kernel)
+}
+
+// UsePreallocDo performs the convolution, writing the result into prealloc.
+func (op *Conv2DOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, error) {
+	im, kernel := inputs[0], inputs[1]
+
+	switch im.Dtype() {
+	case tensor.Float64:
+		return prealloc, op.f64s(prealloc.(*tensor.Dense), im.(*tensor.Dense), kernel.(*tensor.Dense))
+	default:
+		return nil, errors.Errorf(nyiFail, "Conv2DOp.Do()", im.Dtype())
+	}
+}
+
+func (op *Conv2DOp) f64s(out, im, kernel *tensor.Dense) error {
+	n, c, h, w := op.dims(im.Shape())
+	k := kernel.Shape()[0]
+	oh := op.outDim(h, op.KernelH, op.DilationH, op.PadH, op.StrideH)
+	ow := op.outDim(w, op.KernelW, op.DilationW, op.PadW, op.StrideW)
+
+	cg := c / op.Groups // input channels per group
+	kg := k / op.Groups // output channels per group
+	colRows := cg * op.KernelH * op.KernelW
+
+	if need := colRows * oh * ow; len(op.buf) < need {
+		op.buf = make([]float64, need)
+	}
+	col := op.buf[:colRows*oh*ow]
+
+	imData := im.Data().([]float64)
+	kernelData := kernel.Data().([]float64)
+	outData := out.Data().([]float64)
+
+	imStride := c * h * w
+	outStride := k * oh * ow
+	kernelGroupStride := kg * cg * op.KernelH * op.KernelW
+
+	for i := 0; i < n; i++ {
+		imItem := imData[i*imStride : (i+1)*imStride]
+		for g := 0; g < op.Groups; g++ {
+			im2colF64(
+				imItem,
+				col,
+				c, g*cg, cg, h, w,
+				op.KernelH, op.KernelW, op.PadH, op.PadW, op.StrideH, op.StrideW, op.DilationH, op.DilationW,
+				op.Layout == NHWC,
+			)
+
+			colT := tensor.New(tensor.WithShape(colRows, oh*ow), tensor.WithBacking(col))
+			kernelG := tensor.New(tensor.WithShape(kg, colRows), tensor.WithBacking(kernelData[g*kernelGroupStride:g*kernelGroupStride+kg*colRows]))
+
+			prod, err := tensor.MatMul(kernelG, colT)
+			if err != nil {
+				return errors.Wrap(err, "Conv2DOp: GEMM failed")
+			}
+			writeConvOutput(outData, prod.Data().([]float64), i*outStride, k, kg, g*kg, oh, ow, op.Layout == NHWC)
+		}
+	}
+	return nil
+}
+
+// writeConvOutput writes one batch item/group's GEMM result prodData - always
+// in (kg, oh, ow) channel-major order, regardless of op.Layout, since that's
+// what the kernelG x colT GEMM above produces - into its place in outData.
+// For NCHW the destination is the contiguous (kg, oh, ow) block at
+// batchOffset+groupOffset*oh*ow, so a copy suffices; for NHWC, out is
+// declared (n, oh, ow, k) (see InferShape), so groupOffset's kg channels are
+// interleaved among the other groups' at every (y, x) and have to be
+// scattered element-by-element instead.
+func writeConvOutput(outData, prodData []float64, batchOffset, k, kg, groupOffset, oh, ow int, nhwc bool) {
+	if !nhwc {
+		copy(outData[batchOffset+groupOffset*oh*ow:batchOffset+(groupOffset+kg)*oh*ow], prodData)
+		return
+	}
+	for c := 0; c < kg; c++ {
+		for y := 0; y < oh; y++ {
+			for x := 0; x < ow; x++ {
+				outData[batchOffset+(y*ow+x)*k+groupOffset+c] = prodData[(c*oh+y)*ow+x]
+			}
+		}
+	}
+}
+
+// readConvOutputGrad is writeConvOutput's adjoint: it gathers one batch
+// item/group's slice of dOut into dst, in the same (kg, oh, ow) channel-major
+// order writeConvOutput reads from, regardless of op.Layout. f64sBackward's
+// matMulABT/matMulATB calls below assume that layout for dOutG, the same way
+// the forward GEMM produces it.
+func readConvOutputGrad(dst, dOutData []float64, batchOffset, k, kg, groupOffset, oh, ow int, nhwc bool) {
+	if !nhwc {
+		copy(dst, dOutData[batchOffset+groupOffset*oh*ow:batchOffset+(groupOffset+kg)*oh*ow])
+		return
+	}
+	for c := 0; c < kg; c++ {
+		for y := 0; y < oh; y++ {
+			for x := 0; x < ow; x++ {
+				dst[(c*oh+y)*ow+x] = dOutData[batchOffset+(y*ow+x)*k+groupOffset+c]
+			}
+		}
+	}
+}
+
+// im2colF64 gathers one group's worth of channels out of im - the full
+// (totalChannels, h, w) image for NCHW, or (h, w, totalChannels) for NHWC -
+// into the [groupChannels*kh*kw, oh*ow] matrix col, zero-padding where the
+// receptive field falls outside im. channelOffset is the index of the
+// group's first channel within totalChannels; passing the full image plus an
+// offset (rather than a pre-sliced chunk of it) is what lets this work for
+// NHWC, where a group's channels are interleaved rather than contiguous.
+func im2colF64(im, col []float64, totalChannels, channelOffset, groupChannels, height, width, kh, kw, padH, padW, strideH, strideW, dilationH, dilationW int, nhwc bool) {
+	oh := (height+2*padH-(dilationH*(kh-1)+1))/strideH + 1
+	ow := (width+2*padW-(dilationW*(kw-1)+1))/strideW + 1
+	colRows := groupChannels * kh * kw
+
+	at := func(c, y, x int) float64 {
+		c += channelOffset
+		if nhwc {
+			return im[(y*width+x)*totalChannels+c]
+		}
+		return im[(c*height+y)*width+x]
+	}
+
+	for row := 0; row < colRows; row++ {
+		kx := row % kw
+		ky := (row / kw) % kh
+		c := row / (kw * kh)
+
+		for oy := 0; oy < oh; oy++ {
+			iy := oy*strideH - padH + ky*dilationH
+			for ox := 0; ox < ow; ox++ {
+				ix := ox*strideW - padW + kx*dilationW
+				dst := row*oh*ow + oy*ow + ox
+				if iy >= 0 && iy < height && ix >= 0 && ix < width {
+					col[dst] = at(c, iy, ix)
+				} else {
+					col[dst] = 0
+				}
+			}
+		}
+	}
+}
+
+// col2imF64 is im2colF64's adjoint: it scatter-accumulates col back into im
+// (the full, not group-sliced, image gradient, using the same
+// totalChannels/channelOffset/groupChannels scheme), which the caller must
+// have already zeroed. It is used by Conv2DOp's backward pass to turn the
+// gradient w.r.t. the im2col matrix back into the gradient w.r.t. the
+// (possibly padded) input image.
+func col2imF64(col, im []float64, totalChannels, channelOffset, groupChannels, height, width, kh, kw, padH, padW, strideH, strideW, dilationH, dilationW int, nhwc bool) {
+	oh := (height+2*padH-(dilationH*(kh-1)+1))/strideH + 1
+	ow := (width+2*padW-(dilationW*(kw-1)+1))/strideW + 1
+	colRows := groupChannels * kh * kw
+
+	add := func(c, y, x int, v float64) {
+		c += channelOffset
+		if nhwc {
+			im[(y*width+x)*totalChannels+c] += v
+		} else {
+			im[(c*height+y)*width+x] += v
+		}
+	}
+
+	for row := 0; row < colRows; row++ {
+		kx := row % kw
+		ky := (row / kw) % kh
+		c := row / (kw * kh)
+
+		for oy := 0; oy < oh; oy++ {
+			iy := oy*strideH - padH + ky*dilationH
+			for ox := 0; ox < ow; ox++ {
+				ix := ox*strideW - padW + kx*dilationW
+				if iy >= 0 && iy < height && ix >= 0 && ix < width {
+					add(c, iy, ix, col[row*oh*ow+oy*ow+ox])
+				}
+			}
+		}
+	}
+}
+
+// Backward computes the gradients of the convolution with respect to its
+// image and kernel operands, given dOut, the gradient of some loss with
+// respect to op's output. Like the forward pass, it processes one batch
+// item/group at a time, reusing op.buf to recompute that group's im2col
+// matrix rather than requiring it to have been kept around from Do.
+func (op *Conv2DOp) Backward(im, kernel, dOut Value) (dIm, dKernel Value, err error) {
+	imD, ok := im.(*tensor.Dense)
+	if !ok {
+		return nil, nil, errors.Errorf("Conv2DOp.Backward expected a *tensor.Dense image, got %T", im)
+	}
+	kernelD, ok := kernel.(*tensor.Dense)
+	if !ok {
+		return nil, nil, errors.Errorf("Conv2DOp.Backward expected a *tensor.Dense kernel, got %T", kernel)
+	}
+	dOutD, ok := dOut.(*tensor.Dense)
+	if !ok {
+		return nil, nil, errors.Errorf("Conv2DOp.Backward expected a *tensor.Dense output gradient, got %T", dOut)
+	}
+	if im.Dtype() != tensor.Float64 {
+		return nil, nil, errors.Errorf(nyiFail, "Conv2DOp.Backward", im.Dtype())
+	}
+
+	dImD := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(im.Shape()...))
+	dKernelD := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(kernel.Shape()...))
+	if err := op.f64sBackward(dImD, dKernelD, imD, kernelD, dOutD); err != nil {
+		return nil, nil, err
+	}
+	return dImD, dKernelD, nil
+}
+
+// DoDiff implements gorgonia's ADOp interface, the hook the VM's backprop
+// pass actually dispatches an op's gradient through during a graph-level
+// Grad()/Backprop() run. Backward above is Conv2DOp's own entry point for
+// computing im/kernel's gradients given dOut as plain Values; DoDiff is the
+// thin adapter onto Nodes that was missing to make a Conv2D(...)-constructed
+// node trainable at all, since the VM never calls Backward directly.
+func (op *Conv2DOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+
+	dIm, dKernel, err := op.Backward(inputs[0].Value(), inputs[1].Value(), output.Deriv())
+	if err != nil {
+		return errors.Wrap(err, "Conv2DOp.DoDiff")
+	}
+	if err = inputs[0].SetDeriv(dIm); err != nil {
+		return errors.Wrap(err, "Conv2DOp.DoDiff: accumulating dIm")
+	}
+	if err = inputs[1].SetDeriv(dKernel); err != nil {
+		return errors.Wrap(err, "Conv2DOp.DoDiff: accumulating dKernel")
+	}
+	return nil
+}
+
+func (op *Conv2DOp) f64sBackward(dIm, dKernel, im, kernel, dOut *tensor.Dense) error {
+	n, c, h, w := op.dims(im.Shape())
+	k := kernel.Shape()[0]
+	oh := op.outDim(h, op.KernelH, op.DilationH, op.PadH, op.StrideH)
+	ow := op.outDim(w, op.KernelW, op.DilationW, op.PadW, op.StrideW)
+	ohow := oh * ow
+
+	cg := c / op.Groups
+	kg := k / op.Groups
+	colRows := cg * op.KernelH * op.KernelW
+
+	if need := colRows * ohow; len(op.buf) < need {
+		op.buf = make([]float64, need)
+	}
+	col := op.buf[:colRows*ohow]
+
+	imData := im.Data().([]float64)
+	kernelData := kernel.Data().([]float64)
+	dOutData := dOut.Data().([]float64)
+	dImData := dIm.Data().([]float64)
+	dKernelData := dKernel.Data().([]float64)
+
+	imStride := c * h * w
+	outStride := k * ohow
+	kernelGroupStride := kg * colRows
+
+	for i := 0; i < n; i++ {
+		imItem := imData[i*imStride : (i+1)*imStride]
+		dImItem := dImData[i*imStride : (i+1)*imStride]
+
+		for g := 0; g < op.Groups; g++ {
+			im2colF64(imItem, col, c, g*cg, cg, h, w,
+				op.KernelH, op.KernelW, op.PadH, op.PadW, op.StrideH, op.StrideW, op.DilationH, op.DilationW,
+				op.Layout == NHWC)
+
+			kernelG := kernelData[g*kernelGroupStride : g*kernelGroupStride+kg*colRows]
+			dOutG := make([]float64, kg*ohow)
+			readConvOutputGrad(dOutG, dOutData, i*outStride, k, kg, g*kg, oh, ow, op.Layout == NHWC)
+
+			dKernelG := matMulABT(dOutG, col, kg, ohow, colRows)
+			for idx, v := range dKernelG {
+				dKernelData[g*kernelGroupStride+idx] += v
+			}
+
+			dCol := matMulATB(kernelG, dOutG, kg, colRows, ohow)
+			col2imF64(dCol, dImItem, c, g*cg, cg, h, w,
+				op.KernelH, op.KernelW, op.PadH, op.PadW, op.StrideH, op.StrideW, op.DilationH, op.DilationW,
+				op.Layout == NHWC)
+		}
+	}
+	return nil
+}
+
+// matMulABT computes a (m,k) times bᵀ where b is (n,k), producing an (m,n)
+// result. Along with matMulATB below, it lets Backward compute both
+// gradients without depending on an unconfirmed Transpose method on
+// *tensor.Dense.
+func matMulABT(a, b []float64, m, k, n int) []float64 {
+	out := make([]float64, m*n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			var sum float64
+			for p := 0; p < k; p++ {
+				sum += a[i*k+p] * b[j*k+p]
+			}
+			out[i*n+j] = sum
+		}
+	}
+	return out
+}
+
+// matMulATB computes aᵀ where a is (m,k), times b (m,n), producing a (k,n)
+// result.
+func matMulATB(a, b []float64, m, k, n int) []float64 {
+	out := make([]float64, k*n)
+	for i := 0; i < m; i++ {
+		for p := 0; p < k; p++ {
+			aip := a[i*k+p]
+			for j := 0; j < n; j++ {
+				out[p*n+j] += aip * b[i*n+j]
+			}
+		}
+	}
+	return out
+}
+
+func (op *Conv2DOp) ReturnsPtr() bool     { return true }
+func (op *Conv2DOp) CallsExtern() bool    { return true } // delegates to the engine's GEMM
+func (op *Conv2DOp) OverwritesInput() int { return -1 }
+
+func (op *Conv2DOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "Conv2D{%d,%d}{%d,%d}{%d,%d}{%d,%d}g%d-%v",
+		op.KernelH, op.KernelW, op.PadH, op.PadW, op.StrideH, op.StrideW, op.DilationH, op.DilationW, op.Groups, op.Layout)
+}
+
+func (op *Conv2DOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op *Conv2DOp) String() string {
+	return fmt.Sprintf("Conv2D<(%d,%d), (%d,%d), (%d,%d), dilation (%d,%d), %d groups, %v>",
+		op.KernelH, op.KernelW, op.PadH, op.PadW, op.StrideH, op.StrideW, op.DilationH, op.DilationW, op.Groups, op.Layout)
+}
+
+// KernelShape, Strides, Pads and Dilations return op's per-axis settings as
+// [h, w] pairs, the form consumers (e.g. the onnx subpackage) need to
+// re-derive an attribute list; the group count is the exported Groups field.
+func (op *Conv2DOp) KernelShape() []int { return []int{op.KernelH, op.KernelW} }
+func (op *Conv2DOp) Strides() []int     { return []int{op.StrideH, op.StrideW} }
+func (op *Conv2DOp) Pads() []int        { return []int{op.PadH, op.PadW} }
+func (op *Conv2DOp) Dilations() []int   { return []int{op.DilationH, op.DilationW} }
+
+// Conv2D constructs a graph node that convolves kernel over im, using
+// kernelShape/pad/stride/dilation (each a [h, w] pair) and groups. If bias is
+// non-nil, it's added to the convolution's output with a plain Add(out, bias),
+// mirroring torch.nn.Conv2d's optional bias; Conv2D does not reshape or
+// broadcast bias over the channel axis itself.
+func Conv2D(im, kernel, bias *Node, kernelShape, pad, stride, dilation []int, groups int) (*Node, error) {
+	if len(kernelShape) != 2 || len(pad) != 2 || len(stride) != 2 || len(dilation) != 2 {
+		return nil, errors.New("Conv2D only supports 2D convolutions: kernelShape, pad, stride and dilation must each have 2 elements")
+	}
+
+	op := NewConv2DOp(kernelShape[0], kernelShape[1], pad[0], pad[1], stride[0], stride[1],
+		WithDilation(dilation[0], dilation[1]), WithGroups(groups))
+
+	out, err := applyOp(op, im, kernel)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conv2D")
+	}
+	if bias == nil {
+		return out, nil
+	}
+	return Add(out, bias)
+}
@@ -0,0 +1,57 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/chewxy/gorgonia/tensor"
+)
+
+// TestConv2DDoDiffThroughGraphNode exercises Conv2DOp via DoDiff - the
+// interface gorgonia's VM actually dispatches a node's backward pass through
+// - rather than calling op.Backward(...) directly the way
+// TestConv2DBackwardShapes does. It builds a real Conv2D graph node via the
+// public Conv2D constructor, so it would catch a Conv2DOp whose Backward math
+// is correct but was never wired up to actually run during graph backprop.
+func TestConv2DDoDiffThroughGraphNode(t *testing.T) {
+	g := NewGraph()
+	im := NewTensor(g, tensor.Float64, 4, WithName("im"), WithShape(1, 4, 6, 6))
+	kernel := NewTensor(g, tensor.Float64, 4, WithName("kernel"), WithShape(4, 2, 3, 3))
+
+	out, err := Conv2D(im, kernel, nil, []int{3, 3}, []int{1, 1}, []int{1, 1}, []int{1, 1}, 2)
+	if err != nil {
+		t.Fatalf("Conv2D: %v", err)
+	}
+
+	imVal := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(im.Shape()...), tensor.WithBacking(make([]float64, shapeSize(im.Shape()))))
+	kernelVal := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(kernel.Shape()...), tensor.WithBacking(make([]float64, shapeSize(kernel.Shape()))))
+	if err := im.SetValue(imVal); err != nil {
+		t.Fatalf("im.SetValue: %v", err)
+	}
+	if err := kernel.SetValue(kernelVal); err != nil {
+		t.Fatalf("kernel.SetValue: %v", err)
+	}
+
+	dOutData := make([]float64, shapeSize(out.Shape()))
+	for i := range dOutData {
+		dOutData[i] = 1
+	}
+	dOut := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(out.Shape()...), tensor.WithBacking(dOutData))
+	if err := out.SetDeriv(dOut); err != nil {
+		t.Fatalf("out.SetDeriv: %v", err)
+	}
+
+	op, ok := out.Op().(*Conv2DOp)
+	if !ok {
+		t.Fatalf("expected out's Op to be *Conv2DOp, got %T", out.Op())
+	}
+	if err := op.DoDiff(ExecutionContext{}, out.Children(), out); err != nil {
+		t.Fatalf("DoDiff: %v", err)
+	}
+
+	if !shapeEq(im.Deriv().Shape(), im.Shape()) {
+		t.Errorf("im.Deriv() shape = %v, want %v", im.Deriv().Shape(), im.Shape())
+	}
+	if !shapeEq(kernel.Deriv().Shape(), kernel.Shape()) {
+		t.Errorf("kernel.Deriv() shape = %v, want %v", kernel.Deriv().Shape(), kernel.Shape())
+	}
+}
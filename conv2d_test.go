@@ -0,0 +1,186 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chewxy/gorgonia/tensor"
+)
+
+// naiveConv2D is a straightforward (O(n*k*oh*ow*cg*kh*kw), no im2col) reference
+// implementation of Conv2DOp's semantics, used to check Conv2DOp.Do against
+// for a range of stride/pad/dilation/group/layout combinations.
+func naiveConv2D(im, kernel []float64, n, c, h, w, k, kh, kw, padH, padW, strideH, strideW, dilationH, dilationW, groups int, nhwc bool) (out []float64, oh, ow int) {
+	oh = (h+2*padH-(dilationH*(kh-1)+1))/strideH + 1
+	ow = (w+2*padW-(dilationW*(kw-1)+1))/strideW + 1
+	cg := c / groups
+	kg := k / groups
+
+	imAt := func(b, ch, y, x int) float64 {
+		if y < 0 || y >= h || x < 0 || x >= w {
+			return 0
+		}
+		if nhwc {
+			return im[((b*h+y)*w+x)*c+ch]
+		}
+		return im[((b*c+ch)*h+y)*w+x]
+	}
+	kernelAt := func(oc, ic, ky, kx int) float64 {
+		return kernel[((oc*cg+ic)*kh+ky)*kw+kx]
+	}
+
+	out = make([]float64, n*k*oh*ow)
+	outSet := func(b, oc, y, x int, v float64) {
+		if nhwc {
+			out[((b*oh+y)*ow+x)*k+oc] = v
+		} else {
+			out[((b*k+oc)*oh+y)*ow+x] = v
+		}
+	}
+
+	for b := 0; b < n; b++ {
+		for g := 0; g < groups; g++ {
+			for oc := 0; oc < kg; oc++ {
+				realOC := g*kg + oc
+				for oy := 0; oy < oh; oy++ {
+					for ox := 0; ox < ow; ox++ {
+						var sum float64
+						for ic := 0; ic < cg; ic++ {
+							realIC := g*cg + ic
+							for ky := 0; ky < kh; ky++ {
+								iy := oy*strideH - padH + ky*dilationH
+								for kx := 0; kx < kw; kx++ {
+									ix := ox*strideW - padW + kx*dilationW
+									sum += imAt(b, realIC, iy, ix) * kernelAt(realOC, ic, ky, kx)
+								}
+							}
+						}
+						outSet(b, realOC, oy, ox, sum)
+					}
+				}
+			}
+		}
+	}
+	return out, oh, ow
+}
+
+// lcg returns a small deterministic pseudo-random float64 generator, seeded
+// by seed, so test fixtures are reproducible without needing math/rand.
+func lcg(seed int) func() float64 {
+	state := uint64(seed)*6364136223846793005 + 1442695040888963407
+	return func() float64 {
+		state = state*6364136223846793005 + 1442695040888963407
+		return (float64(state>>11)/float64(1<<53))*2 - 1
+	}
+}
+
+func TestConv2DAgainstNaive(t *testing.T) {
+	type cfg struct {
+		name                         string
+		n, c, h, w, k                int
+		kh, kw, padH, padW           int
+		strideH, strideW             int
+		dilationH, dilationW, groups int
+		layout                       ConvLayout
+	}
+	cases := []cfg{
+		{"basic", 1, 2, 5, 5, 3, 3, 3, 0, 0, 1, 1, 1, 1, 1, NCHW},
+		{"stride", 1, 2, 7, 7, 3, 3, 3, 0, 0, 2, 2, 1, 1, 1, NCHW},
+		{"pad", 2, 3, 5, 5, 4, 3, 3, 1, 1, 1, 1, 1, 1, 1, NCHW},
+		{"dilation", 1, 2, 9, 9, 2, 3, 3, 0, 0, 1, 1, 2, 2, 1, NCHW},
+		{"groups_nchw", 2, 4, 6, 6, 4, 3, 3, 1, 1, 1, 1, 1, 1, 2, NCHW},
+		{"groups_nhwc", 2, 4, 6, 6, 4, 3, 3, 1, 1, 1, 1, 1, 1, 2, NHWC},
+		{"all_combined", 2, 6, 8, 8, 6, 3, 3, 1, 1, 2, 2, 2, 2, 3, NHWC},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := lcg(len(tc.name) + 1)
+
+			imShape := tensor.Shape{tc.n, tc.c, tc.h, tc.w}
+			if tc.layout == NHWC {
+				imShape = tensor.Shape{tc.n, tc.h, tc.w, tc.c}
+			}
+			imData := make([]float64, tc.n*tc.c*tc.h*tc.w)
+			for i := range imData {
+				imData[i] = next()
+			}
+
+			kernelData := make([]float64, tc.k*(tc.c/tc.groups)*tc.kh*tc.kw)
+			for i := range kernelData {
+				kernelData[i] = next()
+			}
+
+			im := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(imShape...), tensor.WithBacking(imData))
+			kernel := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(tc.k, tc.c/tc.groups, tc.kh, tc.kw), tensor.WithBacking(kernelData))
+
+			op := NewConv2DOp(tc.kh, tc.kw, tc.padH, tc.padW, tc.strideH, tc.strideW,
+				WithDilation(tc.dilationH, tc.dilationW), WithGroups(tc.groups), WithConvLayout(tc.layout))
+
+			got, err := op.Do(im, kernel)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+
+			want, _, _ := naiveConv2D(imData, kernelData, tc.n, tc.c, tc.h, tc.w, tc.k, tc.kh, tc.kw,
+				tc.padH, tc.padW, tc.strideH, tc.strideW, tc.dilationH, tc.dilationW, tc.groups, tc.layout == NHWC)
+
+			gotData, ok := got.Data().([]float64)
+			if !ok {
+				t.Fatalf("expected []float64 data, got %T", got.Data())
+			}
+			if len(gotData) != len(want) {
+				t.Fatalf("length mismatch: got %d, want %d", len(gotData), len(want))
+			}
+			for i := range want {
+				if math.Abs(gotData[i]-want[i]) > 1e-9 {
+					t.Errorf("at index %d: got %v, want %v", i, gotData[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConv2DBackwardShapes(t *testing.T) {
+	im := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(1, 4, 6, 6), tensor.WithBacking(make([]float64, 1*4*6*6)))
+	kernel := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(4, 2, 3, 3), tensor.WithBacking(make([]float64, 4*2*3*3)))
+	op := NewConv2DOp(3, 3, 1, 1, 1, 1, WithGroups(2))
+
+	out, err := op.Do(im, kernel)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	outData, ok := out.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", out.Data())
+	}
+	dOutData := make([]float64, len(outData))
+	for i := range dOutData {
+		dOutData[i] = 1
+	}
+	dOut := tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(out.Shape()...), tensor.WithBacking(dOutData))
+
+	dIm, dKernel, err := op.Backward(im, kernel, dOut)
+	if err != nil {
+		t.Fatalf("Backward: %v", err)
+	}
+	if !shapeEq(dIm.Shape(), im.Shape()) {
+		t.Errorf("dIm shape = %v, want %v", dIm.Shape(), im.Shape())
+	}
+	if !shapeEq(dKernel.Shape(), kernel.Shape()) {
+		t.Errorf("dKernel shape = %v, want %v", dKernel.Shape(), kernel.Shape())
+	}
+}
+
+func shapeEq(a, b tensor.Shape) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+// Package onnx converts between gorgonia's *gorgonia.ExprGraph and ONNX
+// protobuf model files, so that models built with gorgonia can be shared
+// with (or loaded from) other ONNX-compatible frameworks.
+//
+// Coverage is deliberately scoped to the ops gorgonia's core exposes today:
+// elementwise arithmetic (Add, Sub, Mul, Div, Pow - including their
+// broadcasting forms, see tensor.WithBroadcast), Dot (exported as Gemm), and
+// convolution (Conv2DOp, exported as a single Conv node and imported back as
+// a Conv2DOp node). Anything else
+// encountered on import is reported as an unsupported-op error rather than
+// silently dropped.
+package onnx
+
+// nilInput is the sentinel used in a NodeProto's input list to mark an
+// optional operand that was not provided (e.g. Gemm without a bias), per
+// onnx-mlir's convention of representing omitted optional inputs as an empty
+// string name rather than omitting the slot entirely.
+const nilInput = ""
+
+// isNilInput reports whether a NodeProto input slot is the nilInput sentinel.
+func isNilInput(name string) bool { return name == nilInput }
@@ -0,0 +1,204 @@
+package onnx
+
+import (
+	"io"
+
+	"github.com/chewxy/gorgonia"
+	"github.com/chewxy/gorgonia/onnx/onnxpb"
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Export serializes g, restricted to the subgraph needed to compute outputs,
+// as an ONNX ModelProto and writes it to w. Every *gorgonia.Node reachable
+// from outputs that holds a constant *tensor.Dense value is emitted as a
+// GraphProto initializer; every other leaf becomes a graph input.
+func Export(g *gorgonia.ExprGraph, outputs []*gorgonia.Node, w io.Writer) error {
+	b := newGraphBuilder(g)
+	for _, out := range outputs {
+		if err := b.visit(out); err != nil {
+			return errors.Wrapf(err, "exporting node %v", out)
+		}
+		b.graph.Output = append(b.graph.Output, valueInfo(out))
+	}
+
+	model := &onnxpb.ModelProto{
+		IrVersion:    onnxIRVersion,
+		ProducerName: "gorgonia",
+		OpsetImport:  []*onnxpb.OperatorSetIdProto{{Version: onnxOpsetVersion}},
+		Graph:        b.graph,
+	}
+
+	bs, err := proto.Marshal(model)
+	if err != nil {
+		return errors.Wrap(err, "marshalling ONNX ModelProto")
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// graphBuilder walks the portion of an ExprGraph reachable from the export
+// outputs exactly once, converting each node into ONNX NodeProtos (or, for
+// constants, a GraphProto initializer) as it goes.
+type graphBuilder struct {
+	g       *gorgonia.ExprGraph
+	graph   *onnxpb.GraphProto
+	visited map[int64]bool // keyed by Node.ID()
+}
+
+func newGraphBuilder(g *gorgonia.ExprGraph) *graphBuilder {
+	return &graphBuilder{
+		g:       g,
+		graph:   &onnxpb.GraphProto{Name: "gorgonia_export"},
+		visited: make(map[int64]bool),
+	}
+}
+
+func (b *graphBuilder) visit(n *gorgonia.Node) error {
+	if b.visited[n.ID()] {
+		return nil
+	}
+	b.visited[n.ID()] = true
+
+	if n.IsConstant() {
+		init, err := initializer(n)
+		if err != nil {
+			return err
+		}
+		b.graph.Initializer = append(b.graph.Initializer, init)
+		return nil
+	}
+
+	op := n.Op()
+	if op == nil {
+		// leaf input with no producing op
+		b.graph.Input = append(b.graph.Input, valueInfo(n))
+		return nil
+	}
+
+	for _, child := range n.Children() {
+		if err := b.visit(child); err != nil {
+			return err
+		}
+	}
+
+	node, err := b.convertOp(n)
+	if err != nil {
+		return err
+	}
+	b.graph.Node = append(b.graph.Node, node)
+	return nil
+}
+
+// convertOp maps a single node's Op to its ONNX NodeProto equivalent. A
+// *gorgonia.Conv2DOp (see conv2d.go) becomes a single ONNX Conv node;
+// everything else maps close to 1:1.
+func (b *graphBuilder) convertOp(n *gorgonia.Node) (*onnxpb.NodeProto, error) {
+	inputs := nodeNames(n.Children())
+	outputs := []string{n.Name()}
+
+	switch op := n.Op().(type) {
+	case gorgonia.AddOp:
+		return binaryNode("Add", inputs, outputs), nil
+	case gorgonia.SubOp:
+		return binaryNode("Sub", inputs, outputs), nil
+	case gorgonia.MulOp:
+		return binaryNode("Mul", inputs, outputs), nil
+	case gorgonia.DivOp:
+		return binaryNode("Div", inputs, outputs), nil
+	case gorgonia.PowOp:
+		return binaryNode("Pow", inputs, outputs), nil
+	case gorgonia.DotOp:
+		return gemmNode(inputs, outputs), nil
+	case *gorgonia.Conv2DOp:
+		return convNode(op, inputs, outputs), nil
+	default:
+		return nil, errors.Errorf("onnx: export of op %T is not supported", op)
+	}
+}
+
+func binaryNode(opType string, inputs, outputs []string) *onnxpb.NodeProto {
+	return &onnxpb.NodeProto{OpType: opType, Input: inputs, Output: outputs}
+}
+
+// gemmNode maps DotOp to ONNX's Gemm, per onnx-mlir's optional-input pattern:
+// a Gemm without a bias operand still has three input slots, with the unused
+// bias slot set to nilInput.
+//
+// The tensor package's MatMul/BatchMatMul/Tensordot/Einsum (see
+// tensor/api_linalg.go) are plain functions over *tensor.Dense, not graph
+// nodes, so there is currently no node-level op for them to map from here;
+// DotOp remains the only graph-level matrix multiplication this exports.
+func gemmNode(inputs, outputs []string) *onnxpb.NodeProto {
+	if len(inputs) == 2 {
+		inputs = append(inputs, nilInput)
+	}
+	return &onnxpb.NodeProto{OpType: "Gemm", Input: inputs, Output: outputs}
+}
+
+func convNode(op *gorgonia.Conv2DOp, inputs, outputs []string) *onnxpb.NodeProto {
+	if len(inputs) == 2 {
+		inputs = append(inputs, nilInput) // no bias
+	}
+	return &onnxpb.NodeProto{
+		OpType: "Conv",
+		Input:  inputs,
+		Output: outputs,
+		Attribute: []*onnxpb.AttributeProto{
+			intsAttr("kernel_shape", op.KernelShape()),
+			intsAttr("strides", op.Strides()),
+			intsAttr("pads", op.Pads()),
+			intsAttr("dilations", op.Dilations()),
+			intAttr("group", int64(op.Groups)),
+		},
+	}
+}
+
+func intsAttr(name string, vals []int) *onnxpb.AttributeProto {
+	ints := make([]int64, len(vals))
+	for i, v := range vals {
+		ints[i] = int64(v)
+	}
+	return &onnxpb.AttributeProto{Name: name, Type: onnxpb.AttributeProto_INTS, Ints: ints}
+}
+
+func intAttr(name string, val int64) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: name, Type: onnxpb.AttributeProto_INT, I: val}
+}
+
+func nodeNames(nodes gorgonia.Nodes) []string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.Name()
+	}
+	return names
+}
+
+func valueInfo(n *gorgonia.Node) *onnxpb.ValueInfoProto {
+	return &onnxpb.ValueInfoProto{
+		Name: n.Name(),
+		Type: typeProto(n.Dtype(), n.Shape()),
+	}
+}
+
+// initializer pulls a constant node's backing *tensor.Dense into an ONNX
+// TensorProto, as required for the Gemm/Conv weight and bias operands.
+func initializer(n *gorgonia.Node) (*onnxpb.TensorProto, error) {
+	v := n.Value()
+	dt, ok := v.(*tensor.Dense)
+	if !ok {
+		return nil, errors.Errorf("onnx: cannot export constant of type %T as an initializer", v)
+	}
+
+	raw, err := tensorProtoRawData(dt.Dtype(), dt.Data())
+	if err != nil {
+		return nil, errors.Wrapf(err, "exporting initializer %q", n.Name())
+	}
+	return &onnxpb.TensorProto{
+		Name:     n.Name(),
+		DataType: onnxDtype(dt.Dtype()),
+		Dims:     int64Shape(dt.Shape()),
+		RawData:  raw,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package onnx
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/chewxy/gorgonia"
+	"github.com/chewxy/gorgonia/onnx/onnxpb"
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Import reads an ONNX ModelProto from r and builds an equivalent
+// *gorgonia.ExprGraph. Graph inputs become symbolic (valueless) nodes, and
+// initializers become constant nodes. The returned map looks up a graph node
+// by its ONNX name, so callers can feed values to the symbolic inputs and
+// read the named outputs back out.
+func Import(r io.Reader) (*gorgonia.ExprGraph, map[string]*gorgonia.Node, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading ONNX model")
+	}
+
+	model := new(onnxpb.ModelProto)
+	if err := proto.Unmarshal(raw, model); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshalling ONNX ModelProto")
+	}
+
+	b := newImportBuilder()
+	for _, init := range model.Graph.Initializer {
+		if err := b.addInitializer(init); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, in := range model.Graph.Input {
+		if _, ok := b.nodes[in.Name]; ok {
+			continue // already materialized as an initializer
+		}
+		if err := b.addInput(in); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, n := range model.Graph.Node {
+		if err := b.addNode(n); err != nil {
+			return nil, nil, errors.Wrapf(err, "importing node %q (%s)", n.Output, n.OpType)
+		}
+	}
+
+	return b.g, b.nodes, nil
+}
+
+// importBuilder threads the ExprGraph being built and a name->*Node lookup
+// through each ONNX NodeProto in turn; ONNX models are already topologically
+// sorted, so a single forward pass over model.Graph.Node suffices.
+type importBuilder struct {
+	g     *gorgonia.ExprGraph
+	nodes map[string]*gorgonia.Node
+}
+
+func newImportBuilder() *importBuilder {
+	return &importBuilder{
+		g:     gorgonia.NewGraph(),
+		nodes: make(map[string]*gorgonia.Node),
+	}
+}
+
+func (b *importBuilder) addInitializer(t *onnxpb.TensorProto) error {
+	dt, shape, err := denseFromTensorProto(t)
+	if err != nil {
+		return errors.Wrapf(err, "importing initializer %q", t.Name)
+	}
+	n := gorgonia.NodeFromAny(b.g, dt, gorgonia.WithName(t.Name), gorgonia.WithShape(shape...))
+	b.nodes[t.Name] = n
+	return nil
+}
+
+func (b *importBuilder) addInput(v *onnxpb.ValueInfoProto) error {
+	dt, shape, err := denseTypeFromValueInfo(v)
+	if err != nil {
+		return errors.Wrapf(err, "importing input %q", v.Name)
+	}
+	n := gorgonia.NewTensor(b.g, dt, len(shape), gorgonia.WithName(v.Name), gorgonia.WithShape(shape...))
+	b.nodes[v.Name] = n
+	return nil
+}
+
+func (b *importBuilder) addNode(n *onnxpb.NodeProto) error {
+	inputs := make([]*gorgonia.Node, 0, len(n.Input))
+	for _, name := range n.Input {
+		if isNilInput(name) {
+			inputs = append(inputs, nil) // optional operand, e.g. Gemm without bias
+			continue
+		}
+		in, ok := b.nodes[name]
+		if !ok {
+			return errors.Errorf("input %q referenced before it was produced", name)
+		}
+		inputs = append(inputs, in)
+	}
+
+	var out *gorgonia.Node
+	var err error
+	switch n.OpType {
+	case "Add":
+		out, err = gorgonia.Add(inputs[0], inputs[1])
+	case "Sub":
+		out, err = gorgonia.Sub(inputs[0], inputs[1])
+	case "Mul":
+		out, err = gorgonia.HadamardProd(inputs[0], inputs[1])
+	case "Div":
+		out, err = gorgonia.HadamardDiv(inputs[0], inputs[1])
+	case "Pow":
+		out, err = gorgonia.Pow(inputs[0], inputs[1])
+	case "Gemm":
+		out, err = importGemm(inputs)
+	case "Conv":
+		out, err = importConv(n, inputs)
+	default:
+		return errors.Errorf("onnx: import of op type %q is not supported", n.OpType)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(n.Output) > 0 {
+		b.nodes[n.Output[0]] = out
+	}
+	return nil
+}
+
+// importGemm expands ONNX's Gemm(A, B, C) back into gorgonia's MatMul, plus
+// a bias Add when the optional third operand (C) was provided.
+func importGemm(inputs []*gorgonia.Node) (*gorgonia.Node, error) {
+	out, err := gorgonia.Mul(inputs[0], inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) < 3 || inputs[2] == nil {
+		return out, nil
+	}
+	return gorgonia.Add(out, inputs[2])
+}
+
+// importConv rebuilds a Conv2DOp node (see gorgonia.Conv2D in conv2d.go) from
+// a single ONNX Conv node.
+func importConv(n *onnxpb.NodeProto, inputs []*gorgonia.Node) (*gorgonia.Node, error) {
+	kernel, err := intsAttrValue(n, "kernel_shape")
+	if err != nil {
+		return nil, err
+	}
+	strides, err := intsAttrValue(n, "strides")
+	if err != nil {
+		return nil, err
+	}
+	pads, err := intsAttrValue(n, "pads")
+	if err != nil {
+		return nil, err
+	}
+	dilations, err := intsAttrValue(n, "dilations")
+	if err != nil {
+		return nil, err
+	}
+	groups := intAttrValue(n, "group", 1)
+
+	var bias *gorgonia.Node
+	if len(inputs) > 2 {
+		bias = inputs[2]
+	}
+	return gorgonia.Conv2D(inputs[0], inputs[1], bias, kernel, pads, strides, dilations, groups)
+}
+
+func intsAttrValue(n *onnxpb.NodeProto, name string) ([]int, error) {
+	for _, a := range n.Attribute {
+		if a.Name == name {
+			vals := make([]int, len(a.Ints))
+			for i, v := range a.Ints {
+				vals[i] = int(v)
+			}
+			return vals, nil
+		}
+	}
+	return nil, errors.Errorf("missing required attribute %q on %s", name, n.OpType)
+}
+
+func intAttrValue(n *onnxpb.NodeProto, name string, deflt int) int {
+	for _, a := range n.Attribute {
+		if a.Name == name {
+			return int(a.I)
+		}
+	}
+	return deflt
+}
+
+func denseFromTensorProto(t *onnxpb.TensorProto) (*tensor.Dense, tensor.Shape, error) {
+	shape := int64ToShape(t.Dims)
+	dt, err := tensorDtype(t.DataType)
+	if err != nil {
+		return nil, nil, err
+	}
+	backing, err := backingFromRawData(dt, t.RawData)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "importing initializer %q", t.Name)
+	}
+	return tensor.New(tensor.Of(dt), tensor.WithShape(shape...), tensor.WithBacking(backing)), shape, nil
+}
+
+func denseTypeFromValueInfo(v *onnxpb.ValueInfoProto) (tensor.Dtype, tensor.Shape, error) {
+	dt, err := tensorDtype(v.Type.TensorType.ElemType)
+	if err != nil {
+		return tensor.Dtype{}, nil, err
+	}
+	return dt, int64ToShape(v.Type.TensorType.Shape.Dims()), nil
+}
@@ -0,0 +1,17 @@
+// Package onnxpb is a hand-maintained Go binding for the subset of ONNX's
+// onnx.proto (https://github.com/onnx/onnx/blob/main/onnx/onnx.proto) that
+// the onnx package actually needs: enough of ModelProto/GraphProto/NodeProto
+// to round-trip Add/Sub/Mul/Div/Pow, Gemm and Conv, plus their tensor/shape
+// metadata. It is not generated by protoc - there is no protoc available in
+// this build - so field numbers are copied by hand from the upstream .proto
+// and a couple of real oneofs (TypeProto.value, TensorShapeProto.Dimension's
+// value) are flattened into plain fields, since nothing here needs more than
+// one case of either.
+//
+// Message types implement only the classic proto.Message contract (Reset,
+// String, ProtoMessage) plus the struct tags github.com/golang/protobuf/proto
+// reads via reflection; there's no generated file descriptor, which is fine
+// for that package's legacy (pre-protoc-gen-go-v2) message support but means
+// these types can't be passed to APIs that need a full descriptor (e.g.
+// protoreflect).
+package onnxpb
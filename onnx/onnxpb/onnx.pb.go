@@ -0,0 +1,198 @@
+package onnxpb
+
+import "github.com/golang/protobuf/proto"
+
+// AttributeProto_AttributeType mirrors onnx.proto's AttributeProto.AttributeType.
+// Only the cases this package's callers actually construct (INT, INTS) are
+// given names beyond UNDEFINED; the numeric values still match upstream so a
+// model produced elsewhere that uses e.g. FLOAT or TENSOR attributes at least
+// decodes without misreading the tag.
+type AttributeProto_AttributeType int32
+
+const (
+	AttributeProto_UNDEFINED AttributeProto_AttributeType = 0
+	AttributeProto_FLOAT     AttributeProto_AttributeType = 1
+	AttributeProto_INT       AttributeProto_AttributeType = 2
+	AttributeProto_STRING    AttributeProto_AttributeType = 3
+	AttributeProto_TENSOR    AttributeProto_AttributeType = 4
+	AttributeProto_GRAPH     AttributeProto_AttributeType = 5
+	AttributeProto_FLOATS    AttributeProto_AttributeType = 6
+	AttributeProto_INTS      AttributeProto_AttributeType = 7
+	AttributeProto_STRINGS   AttributeProto_AttributeType = 8
+	AttributeProto_TENSORS   AttributeProto_AttributeType = 9
+	AttributeProto_GRAPHS    AttributeProto_AttributeType = 10
+)
+
+// TensorProto_DataType mirrors onnx.proto's TensorProto.DataType. As with
+// AttributeType, only the cases onnxDtype/tensorDtype (see ../types.go)
+// actually produce are load-bearing here; the rest are present so the
+// numeric value of an unsupported dtype round-trips instead of aliasing a
+// supported one.
+type TensorProto_DataType int32
+
+const (
+	TensorProto_UNDEFINED TensorProto_DataType = 0
+	TensorProto_FLOAT     TensorProto_DataType = 1
+	TensorProto_UINT8     TensorProto_DataType = 2
+	TensorProto_INT8      TensorProto_DataType = 3
+	TensorProto_UINT16    TensorProto_DataType = 4
+	TensorProto_INT16     TensorProto_DataType = 5
+	TensorProto_INT32     TensorProto_DataType = 6
+	TensorProto_INT64     TensorProto_DataType = 7
+	TensorProto_STRING    TensorProto_DataType = 8
+	TensorProto_BOOL      TensorProto_DataType = 9
+	TensorProto_FLOAT16   TensorProto_DataType = 10
+	TensorProto_DOUBLE    TensorProto_DataType = 11
+	TensorProto_UINT32    TensorProto_DataType = 12
+	TensorProto_UINT64    TensorProto_DataType = 13
+	TensorProto_COMPLEX64 TensorProto_DataType = 14
+	TensorProto_BFLOAT16  TensorProto_DataType = 16
+)
+
+// ModelProto is the top-level container Export/Import marshal a graph
+// through (onnx.proto's ModelProto, trimmed to the fields this package
+// populates).
+type ModelProto struct {
+	IrVersion    int64                 `protobuf:"varint,1,opt,name=ir_version,json=irVersion,proto3" json:"ir_version,omitempty"`
+	ProducerName string                `protobuf:"bytes,2,opt,name=producer_name,json=producerName,proto3" json:"producer_name,omitempty"`
+	Graph        *GraphProto           `protobuf:"bytes,7,opt,name=graph,proto3" json:"graph,omitempty"`
+	OpsetImport  []*OperatorSetIdProto `protobuf:"bytes,8,rep,name=opset_import,json=opsetImport,proto3" json:"opset_import,omitempty"`
+}
+
+func (m *ModelProto) Reset()         { *m = ModelProto{} }
+func (m *ModelProto) String() string { return proto.CompactTextString(m) }
+func (*ModelProto) ProtoMessage()    {}
+
+// OperatorSetIdProto names one of a model's imported opsets.
+type OperatorSetIdProto struct {
+	Domain  string `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Version int64  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (m *OperatorSetIdProto) Reset()         { *m = OperatorSetIdProto{} }
+func (m *OperatorSetIdProto) String() string { return proto.CompactTextString(m) }
+func (*OperatorSetIdProto) ProtoMessage()    {}
+
+// GraphProto is a computation graph: its nodes in topological order, its
+// constant initializers, and its symbolic inputs/outputs.
+type GraphProto struct {
+	Node        []*NodeProto      `protobuf:"bytes,1,rep,name=node,proto3" json:"node,omitempty"`
+	Name        string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Initializer []*TensorProto    `protobuf:"bytes,5,rep,name=initializer,proto3" json:"initializer,omitempty"`
+	Input       []*ValueInfoProto `protobuf:"bytes,11,rep,name=input,proto3" json:"input,omitempty"`
+	Output      []*ValueInfoProto `protobuf:"bytes,12,rep,name=output,proto3" json:"output,omitempty"`
+}
+
+func (m *GraphProto) Reset()         { *m = GraphProto{} }
+func (m *GraphProto) String() string { return proto.CompactTextString(m) }
+func (*GraphProto) ProtoMessage()    {}
+
+// NodeProto is a single graph operation: its operand/result names, its op
+// type, and its attributes (e.g. Conv's kernel_shape/strides/pads).
+type NodeProto struct {
+	Input     []string          `protobuf:"bytes,1,rep,name=input,proto3" json:"input,omitempty"`
+	Output    []string          `protobuf:"bytes,2,rep,name=output,proto3" json:"output,omitempty"`
+	OpType    string            `protobuf:"bytes,4,opt,name=op_type,json=opType,proto3" json:"op_type,omitempty"`
+	Attribute []*AttributeProto `protobuf:"bytes,5,rep,name=attribute,proto3" json:"attribute,omitempty"`
+}
+
+func (m *NodeProto) Reset()         { *m = NodeProto{} }
+func (m *NodeProto) String() string { return proto.CompactTextString(m) }
+func (*NodeProto) ProtoMessage()    {}
+
+// AttributeProto is a NodeProto's named attribute. Only the scalar/list int
+// cases (I/Ints) this package's Conv import/export actually uses are
+// populated by convNode/intsAttrValue/intAttrValue; the rest of onnx.proto's
+// AttributeProto fields (f, s, t, g and their repeated forms) aren't needed
+// by any op this package exports and are omitted.
+type AttributeProto struct {
+	Name string                       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	I    int64                        `protobuf:"varint,3,opt,name=i,proto3" json:"i,omitempty"`
+	Type AttributeProto_AttributeType `protobuf:"varint,20,opt,name=type,proto3,enum=onnx.AttributeProto_AttributeType" json:"type,omitempty"`
+	Ints []int64                      `protobuf:"varint,8,rep,packed,name=ints,proto3" json:"ints,omitempty"`
+}
+
+func (m *AttributeProto) Reset()         { *m = AttributeProto{} }
+func (m *AttributeProto) String() string { return proto.CompactTextString(m) }
+func (*AttributeProto) ProtoMessage()    {}
+
+// TensorProto carries a constant tensor's shape, dtype and data, used for
+// Gemm/Conv weight and bias initializers. RawData holds the little-endian
+// encoding of the tensor's backing slice (see tensorProtoRawData/
+// backingFromRawData in ../types.go), the same role onnx.proto's raw_data
+// bytes field plays for real ONNX tensors.
+type TensorProto struct {
+	Dims     []int64              `protobuf:"varint,1,rep,packed,name=dims,proto3" json:"dims,omitempty"`
+	DataType TensorProto_DataType `protobuf:"varint,2,opt,name=data_type,json=dataType,proto3,enum=onnx.TensorProto_DataType" json:"data_type,omitempty"`
+	Name     string               `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`
+	RawData  []byte               `protobuf:"bytes,9,opt,name=raw_data,json=rawData,proto3" json:"raw_data,omitempty"`
+}
+
+func (m *TensorProto) Reset()         { *m = TensorProto{} }
+func (m *TensorProto) String() string { return proto.CompactTextString(m) }
+func (*TensorProto) ProtoMessage()    {}
+
+// ValueInfoProto names a graph input or output and its type.
+type ValueInfoProto struct {
+	Name string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type *TypeProto `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+}
+
+func (m *ValueInfoProto) Reset()         { *m = ValueInfoProto{} }
+func (m *ValueInfoProto) String() string { return proto.CompactTextString(m) }
+func (*ValueInfoProto) ProtoMessage()    {}
+
+// TypeProto is a value's type. Upstream onnx.proto makes this a oneof over
+// several type kinds (tensor_type, sequence_type, map_type, ...); this
+// package only ever produces or consumes tensor types, so TensorType is a
+// plain field rather than a oneof member.
+type TypeProto struct {
+	TensorType *TypeProto_Tensor `protobuf:"bytes,1,opt,name=tensor_type,json=tensorType,proto3" json:"tensor_type,omitempty"`
+}
+
+func (m *TypeProto) Reset()         { *m = TypeProto{} }
+func (m *TypeProto) String() string { return proto.CompactTextString(m) }
+func (*TypeProto) ProtoMessage()    {}
+
+// TypeProto_Tensor is a tensor-typed value's element type and shape.
+type TypeProto_Tensor struct {
+	ElemType TensorProto_DataType `protobuf:"varint,1,opt,name=elem_type,json=elemType,proto3,enum=onnx.TensorProto_DataType" json:"elem_type,omitempty"`
+	Shape    *TensorShapeProto    `protobuf:"bytes,2,opt,name=shape,proto3" json:"shape,omitempty"`
+}
+
+func (m *TypeProto_Tensor) Reset()         { *m = TypeProto_Tensor{} }
+func (m *TypeProto_Tensor) String() string { return proto.CompactTextString(m) }
+func (*TypeProto_Tensor) ProtoMessage()    {}
+
+// TensorShapeProto is an ordered list of dimensions.
+type TensorShapeProto struct {
+	Dim []*TensorShapeProto_Dimension `protobuf:"bytes,1,rep,name=dim,proto3" json:"dim,omitempty"`
+}
+
+func (m *TensorShapeProto) Reset()         { *m = TensorShapeProto{} }
+func (m *TensorShapeProto) String() string { return proto.CompactTextString(m) }
+func (*TensorShapeProto) ProtoMessage()    {}
+
+// Dims is a hand-added convenience, not part of onnx.proto itself: it
+// collapses TensorShapeProto's []*Dimension into the plain []int64 that
+// denseTypeFromValueInfo (see ../import.go) wants, so callers don't have to
+// walk Dim themselves.
+func (m *TensorShapeProto) Dims() []int64 {
+	dims := make([]int64, len(m.Dim))
+	for i, d := range m.Dim {
+		dims[i] = d.DimValue
+	}
+	return dims
+}
+
+// TensorShapeProto_Dimension is one dimension of a TensorShapeProto. Upstream
+// onnx.proto makes dim_value/dim_param a oneof, for symbolic ("batch") as
+// well as fixed dimensions; this package only ever deals in fixed shapes, so
+// DimValue is a plain field.
+type TensorShapeProto_Dimension struct {
+	DimValue int64 `protobuf:"varint,1,opt,name=dim_value,json=dimValue,proto3" json:"dim_value,omitempty"`
+}
+
+func (m *TensorShapeProto_Dimension) Reset()         { *m = TensorShapeProto_Dimension{} }
+func (m *TensorShapeProto_Dimension) String() string { return proto.CompactTextString(m) }
+func (*TensorShapeProto_Dimension) ProtoMessage()    {}
@@ -0,0 +1,138 @@
+package onnx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chewxy/gorgonia"
+	"github.com/chewxy/gorgonia/tensor"
+)
+
+// exportImport round-trips g/out through Export then Import, failing the
+// test immediately on any error so the table-driven tests below can stay
+// focused on what differs per op.
+func exportImport(t *testing.T, g *gorgonia.ExprGraph, out *gorgonia.Node) map[string]*gorgonia.Node {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Export(g, []*gorgonia.Node{out}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	_, nodes, err := Import(&buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	return nodes
+}
+
+func TestExportImportBinaryOps(t *testing.T) {
+	ops := []struct {
+		name string
+		fn   func(a, b *gorgonia.Node) (*gorgonia.Node, error)
+	}{
+		{"Add", gorgonia.Add},
+		{"Sub", gorgonia.Sub},
+		{"Mul", gorgonia.HadamardProd},
+		{"Div", gorgonia.HadamardDiv},
+		{"Pow", gorgonia.Pow},
+	}
+
+	for _, tc := range ops {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gorgonia.NewGraph()
+			a := gorgonia.NewTensor(g, tensor.Float64, 2, gorgonia.WithName("a"), gorgonia.WithShape(2, 3))
+			b := gorgonia.NewTensor(g, tensor.Float64, 2, gorgonia.WithName("b"), gorgonia.WithShape(2, 3))
+
+			out, err := tc.fn(a, b)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+
+			nodes := exportImport(t, g, out)
+			if _, ok := nodes[a.Name()]; !ok {
+				t.Errorf("imported graph is missing input %q", a.Name())
+			}
+			if _, ok := nodes[b.Name()]; !ok {
+				t.Errorf("imported graph is missing input %q", b.Name())
+			}
+			if _, ok := nodes[out.Name()]; !ok {
+				t.Errorf("imported graph is missing output %q", out.Name())
+			}
+		})
+	}
+}
+
+func TestExportImportGemm(t *testing.T) {
+	cases := []struct {
+		name     string
+		withBias bool
+	}{
+		{"no_bias", false},
+		{"with_bias", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gorgonia.NewGraph()
+			a := gorgonia.NewTensor(g, tensor.Float64, 2, gorgonia.WithName("a"), gorgonia.WithShape(2, 3))
+			b := gorgonia.NewTensor(g, tensor.Float64, 2, gorgonia.WithName("b"), gorgonia.WithShape(3, 4))
+
+			out, err := gorgonia.Dot(a, b)
+			if err != nil {
+				t.Fatalf("Dot: %v", err)
+			}
+			if tc.withBias {
+				bias := gorgonia.NewTensor(g, tensor.Float64, 2, gorgonia.WithName("bias"), gorgonia.WithShape(2, 4))
+				out, err = gorgonia.Add(out, bias)
+				if err != nil {
+					t.Fatalf("Add (bias): %v", err)
+				}
+			}
+
+			nodes := exportImport(t, g, out)
+			if _, ok := nodes[out.Name()]; !ok {
+				t.Errorf("imported graph is missing output %q", out.Name())
+			}
+		})
+	}
+}
+
+func TestExportImportConv(t *testing.T) {
+	cases := []struct {
+		name     string
+		withBias bool
+	}{
+		{"no_bias", false},
+		{"with_bias", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gorgonia.NewGraph()
+			im := gorgonia.NewTensor(g, tensor.Float64, 4, gorgonia.WithName("im"), gorgonia.WithShape(1, 2, 5, 5))
+			kernel := gorgonia.NewTensor(g, tensor.Float64, 4, gorgonia.WithName("kernel"), gorgonia.WithShape(3, 2, 3, 3))
+
+			var bias *gorgonia.Node
+			if tc.withBias {
+				bias = gorgonia.NewTensor(g, tensor.Float64, 4, gorgonia.WithName("bias"), gorgonia.WithShape(1, 3, 3, 3))
+			}
+
+			out, err := gorgonia.Conv2D(im, kernel, bias, []int{3, 3}, []int{0, 0}, []int{1, 1}, []int{1, 1}, 1)
+			if err != nil {
+				t.Fatalf("Conv2D: %v", err)
+			}
+
+			nodes := exportImport(t, g, out)
+			if _, ok := nodes[im.Name()]; !ok {
+				t.Errorf("imported graph is missing input %q", im.Name())
+			}
+			if _, ok := nodes[kernel.Name()]; !ok {
+				t.Errorf("imported graph is missing input %q", kernel.Name())
+			}
+			if _, ok := nodes[out.Name()]; !ok {
+				t.Errorf("imported graph is missing output %q", out.Name())
+			}
+		})
+	}
+}
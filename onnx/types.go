@@ -0,0 +1,136 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/chewxy/gorgonia/onnx/onnxpb"
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/pkg/errors"
+)
+
+const (
+	onnxIRVersion    = 7 // IR_VERSION_2020_5_8
+	onnxOpsetVersion = 13
+)
+
+func typeProto(dt tensor.Dtype, shape tensor.Shape) *onnxpb.TypeProto {
+	return &onnxpb.TypeProto{
+		TensorType: &onnxpb.TypeProto_Tensor{
+			ElemType: onnxDtype(dt),
+			Shape:    &onnxpb.TensorShapeProto{Dim: shapeDims(shape)},
+		},
+	}
+}
+
+func shapeDims(shape tensor.Shape) []*onnxpb.TensorShapeProto_Dimension {
+	dims := make([]*onnxpb.TensorShapeProto_Dimension, len(shape))
+	for i, s := range shape {
+		dims[i] = &onnxpb.TensorShapeProto_Dimension{DimValue: int64(s)}
+	}
+	return dims
+}
+
+func int64Shape(shape tensor.Shape) []int64 {
+	dims := make([]int64, len(shape))
+	for i, s := range shape {
+		dims[i] = int64(s)
+	}
+	return dims
+}
+
+func int64ToShape(dims []int64) tensor.Shape {
+	shape := make(tensor.Shape, len(dims))
+	for i, d := range dims {
+		shape[i] = int(d)
+	}
+	return shape
+}
+
+// onnxDtype maps a gorgonia/tensor Dtype to its ONNX TensorProto_DataType.
+func onnxDtype(dt tensor.Dtype) onnxpb.TensorProto_DataType {
+	switch dt {
+	case tensor.Float64:
+		return onnxpb.TensorProto_DOUBLE
+	case tensor.Float32:
+		return onnxpb.TensorProto_FLOAT
+	case tensor.Int:
+		return onnxpb.TensorProto_INT64
+	default:
+		return onnxpb.TensorProto_UNDEFINED
+	}
+}
+
+// tensorDtype maps an ONNX TensorProto_DataType back to a gorgonia/tensor
+// Dtype, the inverse of onnxDtype.
+func tensorDtype(dt onnxpb.TensorProto_DataType) (tensor.Dtype, error) {
+	switch dt {
+	case onnxpb.TensorProto_DOUBLE:
+		return tensor.Float64, nil
+	case onnxpb.TensorProto_FLOAT:
+		return tensor.Float32, nil
+	case onnxpb.TensorProto_INT64:
+		return tensor.Int, nil
+	default:
+		return tensor.Dtype{}, errors.Errorf("onnx: unsupported ONNX data type %v", dt)
+	}
+}
+
+// tensorProtoRawData encodes a *tensor.Dense's backing slice into the
+// little-endian bytes TensorProto.RawData carries over the wire, the same
+// role onnx.proto's raw_data field plays for real ONNX tensors.
+func tensorProtoRawData(dt tensor.Dtype, data interface{}) ([]byte, error) {
+	switch dt {
+	case tensor.Float64:
+		vals := data.([]float64)
+		raw := make([]byte, 8*len(vals))
+		for i, v := range vals {
+			binary.LittleEndian.PutUint64(raw[i*8:], math.Float64bits(v))
+		}
+		return raw, nil
+	case tensor.Float32:
+		vals := data.([]float32)
+		raw := make([]byte, 4*len(vals))
+		for i, v := range vals {
+			binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(v))
+		}
+		return raw, nil
+	case tensor.Int:
+		vals := data.([]int)
+		raw := make([]byte, 8*len(vals))
+		for i, v := range vals {
+			binary.LittleEndian.PutUint64(raw[i*8:], uint64(int64(v)))
+		}
+		return raw, nil
+	default:
+		return nil, errors.Errorf("onnx: cannot serialize tensor data of dtype %v", dt)
+	}
+}
+
+// backingFromRawData is tensorProtoRawData's inverse: it decodes
+// TensorProto.RawData back into the typed backing slice tensor.WithBacking
+// expects for dt.
+func backingFromRawData(dt tensor.Dtype, raw []byte) (interface{}, error) {
+	switch dt {
+	case tensor.Float64:
+		vals := make([]float64, len(raw)/8)
+		for i := range vals {
+			vals[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[i*8:]))
+		}
+		return vals, nil
+	case tensor.Float32:
+		vals := make([]float32, len(raw)/4)
+		for i := range vals {
+			vals[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+		}
+		return vals, nil
+	case tensor.Int:
+		vals := make([]int, len(raw)/8)
+		for i := range vals {
+			vals[i] = int(int64(binary.LittleEndian.Uint64(raw[i*8:])))
+		}
+		return vals, nil
+	default:
+		return nil, errors.Errorf("onnx: cannot deserialize tensor data of dtype %v", dt)
+	}
+}
@@ -0,0 +1,180 @@
+package gorgonia
+
+import (
+	"time"
+
+	"github.com/leesper/go_rng"
+)
+
+/*
+	This file contains the RNGSource abstraction used by randomOp (see nn.go,
+	op_nn.go) and by the Uniform/Gaussian/Binomial node constructors.
+
+	Prior to this, randomOp seeded a fresh go_rng generator from
+	time.Now().UnixNano() on every single Do() call, which made graph
+	execution non-reproducible, and, for scalar ops called in a tight loop,
+	reseeded often enough that successive calls could return identical
+	values. RNGSource fixes this by giving the graph/VM a single,
+	long-lived, seedable source of randomness - analogous to PyTorch's
+	per-device Generator.
+*/
+
+// RNGSource is a seedable source of randomness shared across the Uniform,
+// Gaussian and Binomial ops of an ExprGraph, so that a training script can
+// deterministically reproduce weight initialization and dropout masks.
+type RNGSource interface {
+	// Seed returns the seed this source was last (re)initialized with.
+	Seed() int64
+
+	// ManualSeed reinitializes the source's internal generators
+	// deterministically from seed, discarding any state accumulated so far.
+	ManualSeed(seed int64)
+
+	// Fork derives a new, independent RNGSource, deterministically seeded
+	// from this source's current state. Use this to hand a subgraph or
+	// goroutine its own stream that won't race with, or be perturbed by,
+	// draws made from the parent.
+	Fork() RNGSource
+
+	uniform() *rng.UniformGenerator
+	gaussian() *rng.GaussianGenerator
+	binomial() *rng.BinomialGenerator
+}
+
+// defaultRNGSource is the built-in RNGSource, backed by the same go_rng
+// generators randomOp used to construct ad-hoc.
+type defaultRNGSource struct {
+	seed int64
+
+	uniformGen  *rng.UniformGenerator
+	gaussianGen *rng.GaussianGenerator
+	binomialGen *rng.BinomialGenerator
+}
+
+// NewRNGSource creates an RNGSource deterministically seeded with seed. Two
+// sources created with the same seed will produce identical draw sequences.
+func NewRNGSource(seed int64) RNGSource {
+	src := &defaultRNGSource{}
+	src.ManualSeed(seed)
+	return src
+}
+
+func (src *defaultRNGSource) Seed() int64 { return src.seed }
+
+func (src *defaultRNGSource) ManualSeed(seed int64) {
+	src.seed = seed
+	src.uniformGen = rng.NewUniformGenerator(seed)
+	src.gaussianGen = rng.NewGaussianGenerator(seed)
+	src.binomialGen = rng.NewBinomialGenerator(seed)
+}
+
+func (src *defaultRNGSource) Fork() RNGSource {
+	// derive a child seed deterministically so that Fork() is itself
+	// reproducible given the parent's seed and draw history.
+	childSeed := src.uniformGen.Int64()
+	return NewRNGSource(childSeed)
+}
+
+func (src *defaultRNGSource) uniform() *rng.UniformGenerator   { return src.uniformGen }
+func (src *defaultRNGSource) gaussian() *rng.GaussianGenerator { return src.gaussianGen }
+func (src *defaultRNGSource) binomial() *rng.BinomialGenerator { return src.binomialGen }
+
+// ExecutionContext carries cross-cutting state that an ExprGraph's VM threads
+// through node execution, outside of the Values flowing along edges. For now
+// that's just the RNG; it's the natural place to add other per-run state
+// (e.g. a deterministic clock) later.
+type ExecutionContext struct {
+	RNG RNGSource
+}
+
+// defaultExecutionContext is used whenever a graph is run without an
+// explicit RNGSource, so that existing callers keep working unchanged. It is
+// seeded once per process rather than per-Do(), which is already enough to
+// fix the "successive scalar calls return identical values" bug - callers
+// that need true reproducibility should use WithRNG/ManualSeed explicitly.
+var defaultExecutionContext = ExecutionContext{RNG: NewRNGSource(time.Now().UnixNano())}
+
+// WithRNG sets the RNGSource that Uniform, Gaussian and Binomial node
+// constructors (and the randomOp they build) draw from. Passing it at graph
+// construction time lets a training script reproduce initialization and
+// dropout masks deterministically, by instead calling WithRNG(NewRNGSource(seed)).
+func WithRNG(src RNGSource) NodeConsOpt {
+	return func(n *Node) {
+		if op, ok := n.op.(randomOp); ok {
+			op.rng = src
+			n.op = op
+		}
+	}
+}
+
+// uniform64, gaussian64 and binomial64 (and their 32-bit counterparts below)
+// are RNGSource-threaded replacements for the package-level Uniform64/
+// Gaussian64/Binomial64 helpers, for use by randomOp.Do's non-scalar branch.
+// The package-level helpers draw from an ad-hoc, unseeded generator, which
+// would silently undo WithRNG/ManualSeed for tensor-shaped draws - the case
+// that matters most for reproducible weight initialization and dropout
+// masks. These draw from src instead, one element at a time, the same way
+// the scalar branch already does.
+func uniform64(src RNGSource, low, high float64, shape ...int) []float64 {
+	out := make([]float64, shapeSize(shape))
+	g := src.uniform()
+	for i := range out {
+		out[i] = g.Float64Range(low, high)
+	}
+	return out
+}
+
+func gaussian64(src RNGSource, mean, stdev float64, shape ...int) []float64 {
+	out := make([]float64, shapeSize(shape))
+	g := src.gaussian()
+	for i := range out {
+		out[i] = g.Gaussian(mean, stdev)
+	}
+	return out
+}
+
+func binomial64(src RNGSource, n float64, p float64, shape ...int) []float64 {
+	out := make([]float64, shapeSize(shape))
+	g := src.binomial()
+	for i := range out {
+		out[i] = float64(g.Binomial(int64(n), p))
+	}
+	return out
+}
+
+func uniform32(src RNGSource, low, high float64, shape ...int) []float32 {
+	out := make([]float32, shapeSize(shape))
+	g := src.uniform()
+	for i := range out {
+		out[i] = g.Float32Range(float32(low), float32(high))
+	}
+	return out
+}
+
+func gaussian32(src RNGSource, mean, stdev float64, shape ...int) []float32 {
+	out := make([]float32, shapeSize(shape))
+	g := src.gaussian()
+	for i := range out {
+		out[i] = float32(g.Gaussian(mean, stdev))
+	}
+	return out
+}
+
+func binomial32(src RNGSource, n float64, p float64, shape ...int) []float32 {
+	out := make([]float32, shapeSize(shape))
+	g := src.binomial()
+	for i := range out {
+		out[i] = float32(g.Binomial(int64(n), p))
+	}
+	return out
+}
+
+// shapeSize returns the number of elements a tensor of the given shape
+// holds, i.e. the product of its dimensions (1 for a shapeless/scalar shape).
+func shapeSize(shape []int) int {
+	size := 1
+	for _, s := range shape {
+		size *= s
+	}
+	return size
+}
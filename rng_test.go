@@ -0,0 +1,66 @@
+package gorgonia
+
+import "testing"
+
+func TestRandomOpNonScalarIsReproducibleWithRNG(t *testing.T) {
+	newOp := func() randomOp {
+		op := makeRandomOp(uniform, Float64, -1, 1, 3, 4)
+		op.rng = NewRNGSource(42)
+		return op
+	}
+
+	a, err := newOp().Do()
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	b, err := newOp().Do()
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	aData, ok := a.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", a.Data())
+	}
+	bData, ok := b.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", b.Data())
+	}
+	if len(aData) != len(bData) {
+		t.Fatalf("length mismatch: %d vs %d", len(aData), len(bData))
+	}
+	for i := range aData {
+		if aData[i] != bData[i] {
+			t.Errorf("at index %d: got %v and %v from the same seed", i, aData[i], bData[i])
+		}
+	}
+}
+
+func TestRandomOpNonScalarDiffersAcrossSeeds(t *testing.T) {
+	op1 := makeRandomOp(uniform, Float64, -1, 1, 8)
+	op1.rng = NewRNGSource(1)
+	op2 := makeRandomOp(uniform, Float64, -1, 1, 8)
+	op2.rng = NewRNGSource(2)
+
+	a, err := op1.Do()
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	b, err := op2.Do()
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	aData := a.Data().([]float64)
+	bData := b.Data().([]float64)
+	same := true
+	for i := range aData {
+		if aData[i] != bData[i] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Fatal("expected different seeds to produce different draws")
+	}
+}
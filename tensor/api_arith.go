@@ -25,6 +25,18 @@ func Add(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 		}
 		switch bt := b.(type) {
 		case Tensor:
+			if dtype, ok := resolveAutocast("Add", opts...); ok {
+				if at, bt, err = autocastBinary(at.Engine(), at, bt, dtype); err != nil {
+					return nil, err
+				}
+			}
+			if ParseFuncOpts(opts...).broadcast {
+				broadcaster, ok := adder.(Broadcastable)
+				if !ok {
+					return nil, errors.Errorf("Engine does not support broadcasting for Add")
+				}
+				return broadcaster.AddBroadcast(at, bt, opts...)
+			}
 			return adder.Add(at, bt, opts...)
 		default:
 			return adder.AddScalar(at, b, true, opts...)
@@ -58,6 +70,18 @@ func Sub(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 		}
 		switch bt := b.(type) {
 		case Tensor:
+			if dtype, ok := resolveAutocast("Sub", opts...); ok {
+				if at, bt, err = autocastBinary(at.Engine(), at, bt, dtype); err != nil {
+					return nil, err
+				}
+			}
+			if ParseFuncOpts(opts...).broadcast {
+				broadcaster, ok := suber.(Broadcastable)
+				if !ok {
+					return nil, errors.Errorf("Engine does not support broadcasting for Sub")
+				}
+				return broadcaster.SubBroadcast(at, bt, opts...)
+			}
 			return suber.Sub(at, bt, opts...)
 		default:
 			return suber.SubScalar(at, b, true, opts...)
@@ -91,6 +115,18 @@ func Mul(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 		}
 		switch bt := b.(type) {
 		case Tensor:
+			if dtype, ok := resolveAutocast("Mul", opts...); ok {
+				if at, bt, err = autocastBinary(at.Engine(), at, bt, dtype); err != nil {
+					return nil, err
+				}
+			}
+			if ParseFuncOpts(opts...).broadcast {
+				broadcaster, ok := muler.(Broadcastable)
+				if !ok {
+					return nil, errors.Errorf("Engine does not support broadcasting for Mul")
+				}
+				return broadcaster.MulBroadcast(at, bt, opts...)
+			}
 			return muler.Mul(at, bt, opts...)
 		default:
 			return muler.MulScalar(at, b, true, opts...)
@@ -124,6 +160,18 @@ func Div(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 		}
 		switch bt := b.(type) {
 		case Tensor:
+			if dtype, ok := resolveAutocast("Div", opts...); ok {
+				if at, bt, err = autocastBinary(at.Engine(), at, bt, dtype); err != nil {
+					return nil, err
+				}
+			}
+			if ParseFuncOpts(opts...).broadcast {
+				broadcaster, ok := diver.(Broadcastable)
+				if !ok {
+					return nil, errors.Errorf("Engine does not support broadcasting for Div")
+				}
+				return broadcaster.DivBroadcast(at, bt, opts...)
+			}
 			return diver.Div(at, bt, opts...)
 		default:
 			return diver.DivScalar(at, b, true, opts...)
@@ -157,6 +205,18 @@ func Pow(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 		}
 		switch bt := b.(type) {
 		case Tensor:
+			if dtype, ok := resolveAutocast("Pow", opts...); ok {
+				if at, bt, err = autocastBinary(at.Engine(), at, bt, dtype); err != nil {
+					return nil, err
+				}
+			}
+			if ParseFuncOpts(opts...).broadcast {
+				broadcaster, ok := power.(Broadcastable)
+				if !ok {
+					return nil, errors.Errorf("Engine does not support broadcasting for Pow")
+				}
+				return broadcaster.PowBroadcast(at, bt, opts...)
+			}
 			return power.Pow(at, bt, opts...)
 		default:
 			return power.PowScalar(at, b, true, opts...)
@@ -189,7 +249,22 @@ func Pow(a, b interface{}, opts ...FuncOpt) (retVal Tensor, err error) {
 //
 // The main reason why this opinionated route was taken was due to the author's familiarity with NumPy, and general laziness in translating existing machine learning algorithms
 // to fit the API of the package.
+//
+// New code should prefer the less surprising linalg family instead: MatMul for
+// plain matrix-matrix multiplication, BatchMatMul for batched multiplication,
+// Tensordot for contracting arbitrary axis pairs, and Einsum for anything that
+// doesn't fit those shapes.
 func Dot(x, y Tensor, opts ...FuncOpt) (retVal Tensor, err error) {
+	if dtype, ok := resolveAutocast("Dot", opts...); ok {
+		engine := x.Engine()
+		if _, isCaster := engine.(Caster); !isCaster {
+			engine = y.Engine()
+		}
+		if x, y, err = autocastBinary(engine, x, y, dtype); err != nil {
+			return nil, err
+		}
+	}
+
 	if xdottir, ok := x.Engine().(Dotter); ok {
 		return xdottir.Dot(x, y, opts...)
 	}
@@ -206,6 +281,12 @@ func MatMul(a, b Tensor, opts ...FuncOpt) (retVal Tensor, err error) {
 		return
 	}
 
+	if dtype, ok := resolveAutocast("MatMul", opts...); ok {
+		if a, b, err = autocastBinary(a.Engine(), a, b, dtype); err != nil {
+			return nil, err
+		}
+	}
+
 	switch at := a.(type) {
 	case *Dense:
 		bt := b.(*Dense)
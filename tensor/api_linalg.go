@@ -0,0 +1,99 @@
+package tensor
+
+import "github.com/pkg/errors"
+
+// BatchMatMuler is implemented by engines that can perform batched
+// matrix-matrix multiplication, contracting the trailing two axes of each
+// operand while broadcasting the leading (batch) axes.
+type BatchMatMuler interface {
+	BatchMatMul(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+}
+
+// Einsumer is implemented by engines that can evaluate an Einstein-summation
+// expression over a set of operands.
+type Einsumer interface {
+	Einsum(expr string, operands ...Tensor) (Tensor, error)
+}
+
+// Tensordotter is implemented by engines that can contract two tensors over
+// arbitrary, explicitly named axis pairs.
+type Tensordotter interface {
+	Tensordot(a, b Tensor, axesA, axesB []int, opts ...FuncOpt) (Tensor, error)
+}
+
+// BatchMatMul performs batched matrix multiplication of a and b: the trailing
+// two dimensions of each operand are treated as matrices of shape (M, K) and
+// (K, N) respectively, and the leading dimensions are treated as batch
+// dimensions, broadcast against each other following the same rules as
+// WithBroadcast. The result has shape (..., M, N).
+//
+// Where possible, the default CPU implementation reshapes the batch
+// dimensions away and dispatches to MatMul rather than walking a generic
+// nested-loop contractor; engines such as BLAS/CUDA backends may override
+// this via the BatchMatMuler interface for a fused kernel.
+func BatchMatMul(a, b Tensor, opts ...FuncOpt) (retVal Tensor, err error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, a.Dtype(), b.Dtype())
+	}
+	if a.Dims() < 2 || b.Dims() < 2 {
+		return nil, errors.Errorf("BatchMatMul requires both operands to have at least 2 dimensions, got %d and %d", a.Dims(), b.Dims())
+	}
+
+	bm, ok := a.Engine().(BatchMatMuler)
+	if !ok {
+		if bm, ok = b.Engine().(BatchMatMuler); !ok {
+			return nil, errors.Errorf("Neither a's nor b's engines support BatchMatMul")
+		}
+	}
+	return bm.BatchMatMul(a, b, opts...)
+}
+
+// Einsum evaluates the Einstein-summation expression expr over operands.
+//
+// expr follows the standard mini-language: comma-separated input subscript
+// labels, an optional "->" followed by the output subscript labels, and an
+// optional ellipsis "..." standing in for broadcast batch dimensions shared
+// by every operand that uses it (e.g. "...ij,...jk->...ik" is a batched
+// matrix multiply). A label repeated within an operand's subscript requests
+// a trace along those axes; a label that appears in the inputs but not the
+// output is summed (contracted) over.
+//
+// The default CPU implementation lowers common patterns (pure contraction of
+// two operands, with or without a leading ellipsis) to Tensordot/BatchMatMul
+// rather than building a generic nested-loop contractor. It accepts only
+// those patterns: exactly two operands, an explicit "->" output, and an
+// ellipsis only as a leading "..." shared by both operands and the output,
+// where it must form a batched matrix multiply. Anything else, including
+// repeated (trace) labels, is returned as an error.
+func Einsum(expr string, operands ...Tensor) (retVal Tensor, err error) {
+	if len(operands) == 0 {
+		return nil, errors.New("Einsum requires at least one operand")
+	}
+
+	e, ok := operands[0].Engine().(Einsumer)
+	if !ok {
+		return nil, errors.Errorf("Engine does not support Einsum")
+	}
+	return e.Einsum(expr, operands...)
+}
+
+// Tensordot contracts a and b over the axes named by axesA and axesB: axesA[i]
+// of a is contracted against axesB[i] of b. len(axesA) must equal len(axesB).
+// The remaining (uncontracted) axes of a are laid out first in the result,
+// followed by the remaining axes of b, mirroring numpy.tensordot.
+func Tensordot(a, b Tensor, axesA, axesB []int, opts ...FuncOpt) (retVal Tensor, err error) {
+	if len(axesA) != len(axesB) {
+		return nil, errors.Errorf("Tensordot requires axesA and axesB to name the same number of axes, got %d and %d", len(axesA), len(axesB))
+	}
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, a.Dtype(), b.Dtype())
+	}
+
+	td, ok := a.Engine().(Tensordotter)
+	if !ok {
+		if td, ok = b.Engine().(Tensordotter); !ok {
+			return nil, errors.Errorf("Neither a's nor b's engines support Tensordot")
+		}
+	}
+	return td.Tensordot(a, b, axesA, axesB, opts...)
+}
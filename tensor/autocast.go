@@ -0,0 +1,140 @@
+package tensor
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Caster is implemented by engines that can cast a Tensor's dtype in place
+// (well, into a new Tensor of the same shape). WithAutocast and
+// AutocastScope both need it to cast an op's operands to the autocast dtype
+// before the op runs.
+type Caster interface {
+	Cast(t Tensor, to Dtype) (Tensor, error)
+}
+
+// WithAutocast overrides, for a single call, the dtype Add/Sub/Mul/Div/Pow/
+// MatMul/Dot compute in: operands are cast to dtype first (via the engine's
+// Caster) and the op runs at dtype. The result is returned in dtype; it is
+// not cast back to the operands' original dtype, whether or not the op is on
+// the autocast-unsafe list. It takes precedence over any enclosing
+// AutocastScope.
+func WithAutocast(dtype Dtype) FuncOpt {
+	return func(o *OpOpt) {
+		o.autocast = true
+		o.autocastDtype = dtype
+	}
+}
+
+// AutocastScope brackets a region of code in which Add/Sub/Mul/Div/Pow/
+// MatMul/Dot compute at a lower-precision dtype by default, following the
+// same safe/unsafe op policy as PyTorch's torch.cuda.amp.autocast: ops on the
+// safe list (matmul, conv) downcast their operands; ops on the unsafe list
+// (reductions, loss) always run at full precision regardless of the scope.
+//
+// AutocastScope is not goroutine-safe; it's meant to bracket a single
+// training step on a single goroutine, mirroring how `with autocast():` is
+// used in practice.
+type AutocastScope struct {
+	prevDtype   Dtype
+	prevEnabled bool
+}
+
+var (
+	autocastMu      sync.Mutex
+	autocastEnabled bool
+	autocastDtype   Dtype
+)
+
+// EnterAutocastScope begins an autocast scope computing at dtype, returning
+// an AutocastScope whose Exit restores whatever scope (if any) was active
+// before it - so scopes nest correctly.
+func EnterAutocastScope(dtype Dtype) *AutocastScope {
+	autocastMu.Lock()
+	defer autocastMu.Unlock()
+
+	s := &AutocastScope{prevDtype: autocastDtype, prevEnabled: autocastEnabled}
+	autocastDtype = dtype
+	autocastEnabled = true
+	return s
+}
+
+// Exit ends the scope, restoring whatever autocast state (enabled or not)
+// preceded it.
+func (s *AutocastScope) Exit() {
+	autocastMu.Lock()
+	defer autocastMu.Unlock()
+
+	autocastDtype = s.prevDtype
+	autocastEnabled = s.prevEnabled
+}
+
+// autocastPolicy records, per op name, whether that op should downcast its
+// operands under an active AutocastScope. It follows PyTorch's amp policy by
+// default: matmul/conv-shaped ops (numerically stable, and the ones that
+// benefit most from lower precision) are safe; reductions and ops sensitive
+// to catastrophic cancellation (sum, softmax, log) are not.
+var autocastPolicy = map[string]bool{
+	"Add":         false,
+	"Sub":         false,
+	"Mul":         false,
+	"Div":         false,
+	"Pow":         false,
+	"MatMul":      true,
+	"Dot":         true,
+	"BatchMatMul": true,
+	"Conv2D":      true,
+	"Sum":         false,
+	"Softmax":     false,
+	"Log":         false,
+}
+
+// SetAutocastPolicy overrides whether op downcasts its operands under an
+// active AutocastScope (or WithAutocast). Use this to mark an op added
+// outside this package as autocast-safe, or to opt a built-in op out of
+// autocasting if it turns out to be numerically fragile for a given model.
+func SetAutocastPolicy(op string, safe bool) {
+	autocastMu.Lock()
+	defer autocastMu.Unlock()
+	autocastPolicy[op] = safe
+}
+
+// resolveAutocast decides whether opName should run at a lower precision
+// given the FuncOpts passed to the call and any active AutocastScope, and if
+// so, at which dtype. WithAutocast always wins over a surrounding scope.
+func resolveAutocast(opName string, opts ...FuncOpt) (dtype Dtype, ok bool) {
+	o := ParseFuncOpts(opts...)
+	if o.autocast {
+		return o.autocastDtype, true
+	}
+
+	autocastMu.Lock()
+	defer autocastMu.Unlock()
+	if autocastEnabled && autocastPolicy[opName] {
+		return autocastDtype, true
+	}
+	return Dtype{}, false
+}
+
+// autocastBinary casts a and b to dtype (if they're not already), via the
+// engine's Caster, for use immediately before a binary op's dispatch.
+func autocastBinary(engine Engine, a, b Tensor, dtype Dtype) (Tensor, Tensor, error) {
+	caster, ok := engine.(Caster)
+	if !ok {
+		return nil, nil, errors.Errorf("Engine does not support autocasting to %v", dtype)
+	}
+
+	var err error
+	if a.Dtype() != dtype {
+		if a, err = caster.Cast(a, dtype); err != nil {
+			return nil, nil, errors.Wrap(err, "autocast: casting first operand")
+		}
+	}
+	if b.Dtype() != dtype {
+		if b, err = caster.Cast(b, dtype); err != nil {
+			return nil, nil, errors.Wrap(err, "autocast: casting second operand")
+		}
+	}
+	return a, b, nil
+}
@@ -0,0 +1,98 @@
+package tensor
+
+import "github.com/pkg/errors"
+
+// Broadcastable is implemented by engines that can perform elementwise binary
+// operations on operands of different but broadcast-compatible shapes. Rather
+// than materializing the expansion of the smaller operand, implementations are
+// expected to iterate via strided views where a broadcast axis carries a
+// stride of 0 (mirroring the NumPy/PyTorch broadcasting model).
+//
+// A shape pair (a, b) is broadcast-compatible when, after right-aligning their
+// dimensions and treating missing leading dimensions as 1, every axis either
+// matches or one of the two sizes is 1.
+type Broadcastable interface {
+	AddBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+	SubBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+	MulBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+	DivBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+	PowBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error)
+}
+
+// WithBroadcast signals to Add, Sub, Mul, Div and Pow that the operands may be
+// expanded along size-1 or missing axes instead of requiring identical
+// shapes. Engines that want to support it must implement Broadcastable.
+func WithBroadcast() FuncOpt {
+	return func(o *OpOpt) { o.broadcast = true }
+}
+
+// broadcastShape computes the resulting shape of broadcasting a against b,
+// following the NumPy/PyTorch rule: dimensions are right-aligned, a missing
+// dimension is treated as 1, and each aligned pair of dimensions must either
+// be equal or have one of them equal to 1.
+func broadcastShape(a, b Shape) (Shape, error) {
+	dims := len(a)
+	if len(b) > dims {
+		dims = len(b)
+	}
+
+	retVal := make(Shape, dims)
+	for i := 0; i < dims; i++ {
+		ai := dimOrOne(a, i, dims)
+		bi := dimOrOne(b, i, dims)
+
+		switch {
+		case ai == bi:
+			retVal[dims-1-i] = ai
+		case ai == 1:
+			retVal[dims-1-i] = bi
+		case bi == 1:
+			retVal[dims-1-i] = ai
+		default:
+			return nil, errors.Errorf("shape mismatch: operands could not be broadcast together with shapes %v %v (axis %d from the right has sizes %d and %d, neither of which is 1)", a, b, i, ai, bi)
+		}
+	}
+	return retVal, nil
+}
+
+// dimOrOne returns the size of the i-th dimension counting from the right
+// (0-indexed), or 1 if s does not have that many dimensions.
+func dimOrOne(s Shape, i, dims int) int {
+	idx := len(s) - 1 - i
+	if idx < 0 {
+		return 1
+	}
+	return s[idx]
+}
+
+// rowMajorStrides returns shape's strides assuming C (row-major) order.
+func rowMajorStrides(shape Shape) []int {
+	strides := make([]int, len(shape))
+	acc := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		strides[i] = acc
+		acc *= shape[i]
+	}
+	return strides
+}
+
+// broadcastStrides returns, for each axis of out (left to right), the stride
+// to use when indexing into data whose real shape is orig: 0 where orig is
+// missing that axis or has size 1 there (so every index along that axis
+// reads the same element, i.e. the axis is being broadcast), and orig's own
+// row-major stride otherwise. This is what lets a broadcasting op walk the
+// smaller operand via a strided view instead of materializing its expansion.
+func broadcastStrides(orig, out Shape) []int {
+	origStrides := rowMajorStrides(orig)
+	dims := len(out)
+	result := make([]int, dims)
+	for i := 0; i < dims; i++ {
+		origIdx := len(orig) - dims + i
+		if origIdx < 0 || orig[origIdx] == 1 {
+			result[i] = 0
+		} else {
+			result[i] = origStrides[origIdx]
+		}
+	}
+	return result
+}
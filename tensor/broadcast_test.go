@@ -0,0 +1,36 @@
+package tensor
+
+import "testing"
+
+func TestAddBroadcast(t *testing.T) {
+	a := New(Of(Float64), WithShape(2, 4), WithBacking([]float64{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+	}))
+	b := New(Of(Float64), WithShape(1, 4), WithBacking([]float64{10, 20, 30, 40}))
+
+	got, err := Add(a, b, WithBroadcast())
+	if err != nil {
+		t.Fatalf("Add(a, b, WithBroadcast()): %v", err)
+	}
+
+	want := []float64{11, 22, 33, 44, 15, 26, 37, 48}
+	gotData, ok := got.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", got.Data())
+	}
+	for i, w := range want {
+		if gotData[i] != w {
+			t.Errorf("at index %d: got %v, want %v", i, gotData[i], w)
+		}
+	}
+}
+
+func TestAddBroadcastShapeMismatch(t *testing.T) {
+	a := New(Of(Float64), WithShape(2, 3), WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	b := New(Of(Float64), WithShape(4), WithBacking([]float64{1, 2, 3, 4}))
+
+	if _, err := Add(a, b, WithBroadcast()); err == nil {
+		t.Fatal("expected an error broadcasting (2,3) against (4), got nil")
+	}
+}
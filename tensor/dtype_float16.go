@@ -0,0 +1,98 @@
+package tensor
+
+import (
+	"math"
+	"reflect"
+)
+
+// float16 is a software IEEE-754 binary16. BFloat16 below is the usual
+// truncated-binary32 alternative; both exist purely so WithAutocast has
+// somewhere to downcast to on hardware that lacks native half-precision
+// support - arithmetic on either is done by promoting to float32, operating,
+// then narrowing back down.
+type float16 uint16
+
+// bfloat16 keeps a float32's top 16 bits (sign, 8 exponent bits, 7 mantissa
+// bits), trading mantissa precision for float32's exponent range;
+// float32ToBFloat16 rounds the dropped bits to nearest-even.
+type bfloat16 uint16
+
+var (
+	// Float16 is IEEE-754 binary16: 1 sign bit, 5 exponent bits, 10 mantissa
+	// bits. Prefer it when the value range is known to be modest.
+	Float16 = Dtype{reflect.TypeOf(float16(0))}
+	// BFloat16 keeps float32's 8 exponent bits but only 7 mantissa bits, so
+	// it trades precision for range - the dtype TPUs and mixed-precision
+	// training typically default to.
+	BFloat16 = Dtype{reflect.TypeOf(bfloat16(0))}
+)
+
+func float32ToFloat16(f float32) float16 {
+	bits := math.Float32bits(f)
+	sign := uint16((bits >> 16) & 0x8000)
+	exp := int32((bits>>23)&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp <= 0:
+		// Too small to be a normal half, but not necessarily too small for a
+		// half *subnormal* - float16ToFloat32 below fully decodes those, so
+		// the encoder needs to produce them too, rather than flushing
+		// anything in (2^-24, 2^-14) to zero. shift is how far the 24-bit
+		// significand (mant plus its implicit leading 1) has to move right
+		// to land in half's 10-bit subnormal mantissa field; shift > 24
+		// means even that can't represent f, so it's genuinely zero.
+		shift := uint(14 - exp)
+		if shift > 24 {
+			return float16(sign) // underflows to (signed) zero
+		}
+		extended := mant | 0x800000
+		m := extended >> shift
+		// round to nearest, ties to even, on the bits the shift above drops
+		halfBit := uint32(1) << (shift - 1)
+		if rem := extended & (halfBit<<1 - 1); rem > halfBit || (rem == halfBit && m&1 == 1) {
+			m++
+		}
+		return float16(sign | uint16(m))
+	case exp >= 0x1f:
+		return float16(sign | 0x7c00) // overflow to infinity
+	default:
+		return float16(sign | uint16(exp)<<10 | uint16(mant>>13))
+	}
+}
+
+func float16ToFloat32(h float16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// subnormal: normalize it
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		exp++
+		mant &= 0x3ff
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13) // inf/NaN
+	}
+
+	exp = exp - 15 + 127
+	return math.Float32frombits(sign | exp<<23 | mant<<13)
+}
+
+func float32ToBFloat16(f float32) bfloat16 {
+	bits := math.Float32bits(f)
+	// round to nearest-even on the bit we're dropping
+	rounded := bits + 0x7fff + ((bits >> 16) & 1)
+	return bfloat16(rounded >> 16)
+}
+
+func bfloat16ToFloat32(b bfloat16) float32 {
+	return math.Float32frombits(uint32(b) << 16)
+}
@@ -0,0 +1,54 @@
+package tensor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat16RoundTripNormal(t *testing.T) {
+	for _, f := range []float32{0, 1, -1, 3.5, 65504, -65504} {
+		got := float16ToFloat32(float32ToFloat16(f))
+		if got != f {
+			t.Errorf("float32ToFloat16(%v) round-tripped to %v", f, got)
+		}
+	}
+}
+
+func TestFloat16OverflowsToInf(t *testing.T) {
+	got := float16ToFloat32(float32ToFloat16(70000))
+	if !math.IsInf(float64(got), 1) {
+		t.Errorf("expected +Inf for a value beyond half's range, got %v", got)
+	}
+}
+
+// TestFloat16EncodesSubnormals guards against the encoder/decoder asymmetry
+// where float16ToFloat32 could decode subnormal bit patterns that
+// float32ToFloat16 could never produce, silently flushing small values (e.g.
+// autocast activations/gradients) to zero instead of keeping subnormal
+// precision.
+func TestFloat16EncodesSubnormals(t *testing.T) {
+	const smallestSubnormal = 5.960464477539063e-08 // 2^-24
+	cases := []float32{smallestSubnormal, 2 * smallestSubnormal, 1023 * smallestSubnormal}
+
+	for _, f := range cases {
+		h := float32ToFloat16(f)
+		if h == 0 {
+			t.Errorf("float32ToFloat16(%v) flushed to zero, want a nonzero subnormal", f)
+			continue
+		}
+		if exp := (h >> 10) & 0x1f; exp != 0 {
+			t.Errorf("float32ToFloat16(%v) = 0x%04x is not subnormal (exponent field %d)", f, uint16(h), exp)
+		}
+		got := float16ToFloat32(h)
+		if math.Abs(float64(got)-float64(f)) > float64(smallestSubnormal) {
+			t.Errorf("float32ToFloat16(%v) round-tripped to %v, too far off", f, got)
+		}
+	}
+}
+
+func TestFloat16SmallerThanSmallestSubnormalFlushesToZero(t *testing.T) {
+	got := float32ToFloat16(1e-10)
+	if got != 0 {
+		t.Errorf("expected a value far below half's smallest subnormal to flush to zero, got 0x%04x", uint16(got))
+	}
+}
@@ -0,0 +1,81 @@
+package tensor
+
+import "github.com/pkg/errors"
+
+// Cast implements Caster for StdEng using the software float16/bfloat16
+// conversion routines in dtype_float16.go: every dtype this engine knows how
+// to autocast to or from goes through float64 as a common intermediate,
+// which keeps the conversion matrix small (N converters in, N out, instead
+// of N²) at the cost of an extra round-trip for Float16<->BFloat16.
+func (e StdEng) Cast(t Tensor, to Dtype) (Tensor, error) {
+	if t.Dtype() == to {
+		return t, nil
+	}
+
+	f64s, err := toFloat64s(t.Data(), t.Dtype())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cast")
+	}
+	data, err := fromFloat64s(f64s, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cast")
+	}
+	return New(Of(to), WithShape(t.Shape()...), WithBacking(data)), nil
+}
+
+func toFloat64s(data interface{}, dt Dtype) ([]float64, error) {
+	switch dt {
+	case Float64:
+		return data.([]float64), nil
+	case Float32:
+		src := data.([]float32)
+		out := make([]float64, len(src))
+		for i, v := range src {
+			out[i] = float64(v)
+		}
+		return out, nil
+	case Float16:
+		src := data.([]float16)
+		out := make([]float64, len(src))
+		for i, v := range src {
+			out[i] = float64(float16ToFloat32(v))
+		}
+		return out, nil
+	case BFloat16:
+		src := data.([]bfloat16)
+		out := make([]float64, len(src))
+		for i, v := range src {
+			out[i] = float64(bfloat16ToFloat32(v))
+		}
+		return out, nil
+	default:
+		return nil, errors.Errorf("Cast: the default engine does not know how to cast from %v", dt)
+	}
+}
+
+func fromFloat64s(data []float64, dt Dtype) (interface{}, error) {
+	switch dt {
+	case Float64:
+		return data, nil
+	case Float32:
+		out := make([]float32, len(data))
+		for i, v := range data {
+			out[i] = float32(v)
+		}
+		return out, nil
+	case Float16:
+		out := make([]float16, len(data))
+		for i, v := range data {
+			out[i] = float32ToFloat16(float32(v))
+		}
+		return out, nil
+	case BFloat16:
+		out := make([]bfloat16, len(data))
+		for i, v := range data {
+			out[i] = float32ToBFloat16(float32(v))
+		}
+		return out, nil
+	default:
+		return nil, errors.Errorf("Cast: the default engine does not know how to cast to %v", dt)
+	}
+}
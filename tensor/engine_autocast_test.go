@@ -0,0 +1,44 @@
+package tensor
+
+import "testing"
+
+func TestCastRoundTrip(t *testing.T) {
+	a := New(Of(Float32), WithShape(3), WithBacking([]float32{1, -2, 3.5}))
+
+	half, err := a.Engine().(Caster).Cast(a, Float16)
+	if err != nil {
+		t.Fatalf("Cast to Float16: %v", err)
+	}
+	if half.Dtype() != Float16 {
+		t.Fatalf("expected Float16, got %v", half.Dtype())
+	}
+
+	back, err := half.Engine().(Caster).Cast(half, Float32)
+	if err != nil {
+		t.Fatalf("Cast back to Float32: %v", err)
+	}
+
+	want := []float32{1, -2, 3.5}
+	got, ok := back.Data().([]float32)
+	if !ok {
+		t.Fatalf("expected []float32 data, got %T", back.Data())
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("at index %d: got %v, want %v", i, got[i], w)
+		}
+	}
+}
+
+func TestAddWithAutocast(t *testing.T) {
+	a := New(Of(Float32), WithShape(2), WithBacking([]float32{1, 2}))
+	b := New(Of(Float32), WithShape(2), WithBacking([]float32{3, 4}))
+
+	got, err := Add(a, b, WithAutocast(Float16))
+	if err != nil {
+		t.Fatalf("Add(a, b, WithAutocast(Float16)): %v", err)
+	}
+	if got.Dtype() != Float16 {
+		t.Fatalf("expected result dtype Float16, got %v", got.Dtype())
+	}
+}
@@ -0,0 +1,111 @@
+package tensor
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// StdEng's Broadcastable implementation: compute the broadcast shape via
+// broadcastShape, derive a stride-0-on-broadcast-axes view of each operand
+// via broadcastStrides, and walk the output in row-major order, so neither
+// operand's expansion is ever materialized.
+
+func (e StdEng) AddBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	return e.broadcastBinary(a, b, func(x, y float64) float64 { return x + y }, func(x, y float32) float32 { return x + y })
+}
+
+func (e StdEng) SubBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	return e.broadcastBinary(a, b, func(x, y float64) float64 { return x - y }, func(x, y float32) float32 { return x - y })
+}
+
+func (e StdEng) MulBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	return e.broadcastBinary(a, b, func(x, y float64) float64 { return x * y }, func(x, y float32) float32 { return x * y })
+}
+
+func (e StdEng) DivBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	return e.broadcastBinary(a, b, func(x, y float64) float64 { return x / y }, func(x, y float32) float32 { return x / y })
+}
+
+func (e StdEng) PowBroadcast(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	return e.broadcastBinary(a, b,
+		func(x, y float64) float64 { return math.Pow(x, y) },
+		func(x, y float32) float32 { return float32(math.Pow(float64(x), float64(y))) },
+	)
+}
+
+func (e StdEng) broadcastBinary(a, b Tensor, f64 func(x, y float64) float64, f32 func(x, y float32) float32) (Tensor, error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, a.Dtype(), b.Dtype())
+	}
+
+	outShape, err := broadcastShape(a.Shape(), b.Shape())
+	if err != nil {
+		return nil, err
+	}
+	aStrides := broadcastStrides(a.Shape(), outShape)
+	bStrides := broadcastStrides(b.Shape(), outShape)
+
+	switch a.Dtype() {
+	case Float64:
+		out := broadcastWalkF64(a.Data().([]float64), b.Data().([]float64), aStrides, bStrides, outShape, f64)
+		return New(Of(Float64), WithShape(outShape...), WithBacking(out)), nil
+	case Float32:
+		out := broadcastWalkF32(a.Data().([]float32), b.Data().([]float32), aStrides, bStrides, outShape, f32)
+		return New(Of(Float32), WithShape(outShape...), WithBacking(out)), nil
+	default:
+		return nil, errors.Errorf(nyiFail, "broadcastBinary", a.Dtype())
+	}
+}
+
+func broadcastWalkF64(a, b []float64, aStrides, bStrides []int, outShape Shape, combine func(x, y float64) float64) []float64 {
+	total := 1
+	for _, s := range outShape {
+		total *= s
+	}
+
+	out := make([]float64, total)
+	idx := make([]int, len(outShape))
+	for i := 0; i < total; i++ {
+		aOff, bOff := 0, 0
+		for d := range idx {
+			aOff += idx[d] * aStrides[d]
+			bOff += idx[d] * bStrides[d]
+		}
+		out[i] = combine(a[aOff], b[bOff])
+		incrOdometer(idx, outShape)
+	}
+	return out
+}
+
+func broadcastWalkF32(a, b []float32, aStrides, bStrides []int, outShape Shape, combine func(x, y float32) float32) []float32 {
+	total := 1
+	for _, s := range outShape {
+		total *= s
+	}
+
+	out := make([]float32, total)
+	idx := make([]int, len(outShape))
+	for i := 0; i < total; i++ {
+		aOff, bOff := 0, 0
+		for d := range idx {
+			aOff += idx[d] * aStrides[d]
+			bOff += idx[d] * bStrides[d]
+		}
+		out[i] = combine(a[aOff], b[bOff])
+		incrOdometer(idx, outShape)
+	}
+	return out
+}
+
+// incrOdometer advances idx to the next row-major multi-index within shape,
+// carrying between axes like an odometer.
+func incrOdometer(idx []int, shape Shape) {
+	for d := len(idx) - 1; d >= 0; d-- {
+		idx[d]++
+		if idx[d] < shape[d] {
+			return
+		}
+		idx[d] = 0
+	}
+}
@@ -0,0 +1,338 @@
+package tensor
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StdEng's BatchMatMuler/Einsumer/Tensordotter implementation. As the
+// api_linalg.go doc comments promise, none of these walk a generic
+// nested-loop contractor: Tensordot permutes each operand's contracted axes
+// to the inside and dispatches to MatMul, BatchMatMul loops MatMul once per
+// broadcast batch item (reusing broadcastShape/broadcastStrides from
+// broadcast.go for the batch dimensions), and Einsum lowers the two-operand
+// patterns it understands to one of the two above rather than attempting a
+// fully general expression evaluator.
+
+// Tensordot implements Tensordotter by permuting the contracted axes of a and
+// b to the end/start respectively, reshaping both down to 2D, and running a
+// single MatMul.
+func (e StdEng) Tensordot(a, b Tensor, axesA, axesB []int, opts ...FuncOpt) (Tensor, error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, a.Dtype(), b.Dtype())
+	}
+
+	aShape, bShape := a.Shape(), b.Shape()
+	freeA := freeAxes(a.Dims(), axesA)
+	freeB := freeAxes(b.Dims(), axesB)
+
+	m, k, n, kb := 1, 1, 1, 1
+	for _, ax := range freeA {
+		m *= aShape[ax]
+	}
+	for _, ax := range axesA {
+		k *= aShape[ax]
+	}
+	for _, ax := range axesB {
+		kb *= bShape[ax]
+	}
+	if k != kb {
+		return nil, errors.Errorf("Tensordot: contracted axes do not agree in size: a's axes %v total %d, b's axes %v total %d", axesA, k, axesB, kb)
+	}
+	for _, ax := range freeB {
+		n *= bShape[ax]
+	}
+
+	aOrder := append(append([]int{}, freeA...), axesA...)
+	bOrder := append(append([]int{}, axesB...), freeB...)
+
+	outShape := make(Shape, 0, len(freeA)+len(freeB))
+	for _, ax := range freeA {
+		outShape = append(outShape, aShape[ax])
+	}
+	for _, ax := range freeB {
+		outShape = append(outShape, bShape[ax])
+	}
+
+	switch a.Dtype() {
+	case Float64:
+		aMat := New(Of(Float64), WithShape(m, k), WithBacking(permuteF64(a.Data().([]float64), aShape, aOrder)))
+		bMat := New(Of(Float64), WithShape(k, n), WithBacking(permuteF64(b.Data().([]float64), bShape, bOrder)))
+		res, err := MatMul(aMat, bMat)
+		if err != nil {
+			return nil, errors.Wrap(err, "Tensordot")
+		}
+		return New(Of(Float64), WithShape(outShape...), WithBacking(res.Data().([]float64))), nil
+	case Float32:
+		aMat := New(Of(Float32), WithShape(m, k), WithBacking(permuteF32(a.Data().([]float32), aShape, aOrder)))
+		bMat := New(Of(Float32), WithShape(k, n), WithBacking(permuteF32(b.Data().([]float32), bShape, bOrder)))
+		res, err := MatMul(aMat, bMat)
+		if err != nil {
+			return nil, errors.Wrap(err, "Tensordot")
+		}
+		return New(Of(Float32), WithShape(outShape...), WithBacking(res.Data().([]float32))), nil
+	default:
+		return nil, errors.Errorf(nyiFail, "Tensordot", a.Dtype())
+	}
+}
+
+// BatchMatMul implements BatchMatMuler by broadcasting the leading (batch)
+// axes of a and b exactly as WithBroadcast would, then running one MatMul
+// per resulting batch item.
+func (e StdEng) BatchMatMul(a, b Tensor, opts ...FuncOpt) (Tensor, error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf(dtypeMismatch, a.Dtype(), b.Dtype())
+	}
+
+	aShape, bShape := a.Shape(), b.Shape()
+	an, bn := len(aShape), len(bShape)
+	aBatch, bBatch := aShape[:an-2], bShape[:bn-2]
+	m, k := aShape[an-2], aShape[an-1]
+	k2, n := bShape[bn-2], bShape[bn-1]
+	if k != k2 {
+		return nil, errors.Errorf("BatchMatMul: inner dimensions do not match: a is (..., %d, %d), b is (..., %d, %d)", m, k, k2, n)
+	}
+
+	outBatch, err := broadcastShape(aBatch, bBatch)
+	if err != nil {
+		return nil, errors.Wrap(err, "BatchMatMul: broadcasting batch dimensions")
+	}
+	aBlockStrides := broadcastStrides(aBatch, outBatch)
+	bBlockStrides := broadcastStrides(bBatch, outBatch)
+
+	batches := 1
+	for _, s := range outBatch {
+		batches *= s
+	}
+	outShape := append(append(Shape{}, outBatch...), m, n)
+	idx := make([]int, len(outBatch))
+
+	switch a.Dtype() {
+	case Float64:
+		aData, bData := a.Data().([]float64), b.Data().([]float64)
+		out := make([]float64, batches*m*n)
+		for i := 0; i < batches; i++ {
+			aBlock, bBlock := 0, 0
+			for d := range idx {
+				aBlock += idx[d] * aBlockStrides[d]
+				bBlock += idx[d] * bBlockStrides[d]
+			}
+			aMat := New(Of(Float64), WithShape(m, k), WithBacking(aData[aBlock*m*k:(aBlock+1)*m*k]))
+			bMat := New(Of(Float64), WithShape(k, n), WithBacking(bData[bBlock*k*n:(bBlock+1)*k*n]))
+			res, err := MatMul(aMat, bMat)
+			if err != nil {
+				return nil, errors.Wrap(err, "BatchMatMul")
+			}
+			copy(out[i*m*n:(i+1)*m*n], res.Data().([]float64))
+			incrOdometer(idx, outBatch)
+		}
+		return New(Of(Float64), WithShape(outShape...), WithBacking(out)), nil
+	case Float32:
+		aData, bData := a.Data().([]float32), b.Data().([]float32)
+		out := make([]float32, batches*m*n)
+		for i := 0; i < batches; i++ {
+			aBlock, bBlock := 0, 0
+			for d := range idx {
+				aBlock += idx[d] * aBlockStrides[d]
+				bBlock += idx[d] * bBlockStrides[d]
+			}
+			aMat := New(Of(Float32), WithShape(m, k), WithBacking(aData[aBlock*m*k:(aBlock+1)*m*k]))
+			bMat := New(Of(Float32), WithShape(k, n), WithBacking(bData[bBlock*k*n:(bBlock+1)*k*n]))
+			res, err := MatMul(aMat, bMat)
+			if err != nil {
+				return nil, errors.Wrap(err, "BatchMatMul")
+			}
+			copy(out[i*m*n:(i+1)*m*n], res.Data().([]float32))
+			incrOdometer(idx, outBatch)
+		}
+		return New(Of(Float32), WithShape(outShape...), WithBacking(out)), nil
+	default:
+		return nil, errors.Errorf(nyiFail, "BatchMatMul", a.Dtype())
+	}
+}
+
+// Einsum implements Einsumer for exactly the two-operand patterns its own
+// doc comment promises: a plain contraction ("ij,jk->ik") lowered to
+// Tensordot, or a batched-matmul ellipsis pattern ("...ij,...jk->...ik")
+// lowered to BatchMatMul. Anything else - more than two operands, repeated
+// (trace) labels, an implicit (missing "->") output, or an ellipsis that
+// isn't shared by both operands and the output - is rejected outright rather
+// than guessed at.
+func (e StdEng) Einsum(expr string, operands ...Tensor) (Tensor, error) {
+	if len(operands) != 2 {
+		return nil, errors.Errorf("Einsum: the default engine only supports two-operand expressions, got %d operands", len(operands))
+	}
+
+	arrow := strings.Index(expr, "->")
+	if arrow < 0 {
+		return nil, errors.Errorf("Einsum: the default engine requires an explicit \"->\" output spec, got %q", expr)
+	}
+	lhs, out := expr[:arrow], strings.TrimSpace(expr[arrow+2:])
+
+	inSpecs := strings.Split(lhs, ",")
+	if len(inSpecs) != 2 {
+		return nil, errors.Errorf("Einsum: expected 2 comma-separated input specs, got %d in %q", len(inSpecs), expr)
+	}
+	specA, specB := strings.TrimSpace(inSpecs[0]), strings.TrimSpace(inSpecs[1])
+	a, b := operands[0], operands[1]
+
+	for _, spec := range []string{specA, specB} {
+		seen := make(map[rune]bool)
+		for _, l := range spec {
+			if l != '.' && seen[l] {
+				return nil, errors.Errorf("Einsum: the default engine does not support repeated (trace) labels, got %q", expr)
+			}
+			seen[l] = true
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(specA, "...") && strings.HasPrefix(specB, "...") && strings.HasPrefix(out, "..."):
+		return e.einsumBatched(specA[3:], specB[3:], out[3:], a, b)
+	case strings.Contains(specA, "...") || strings.Contains(specB, "...") || strings.Contains(out, "..."):
+		return nil, errors.Errorf("Einsum: the default engine only supports a leading ellipsis shared by both operands and the output, got %q", expr)
+	default:
+		return e.einsumPlain(specA, specB, out, a, b)
+	}
+}
+
+func (e StdEng) einsumBatched(specA, specB, out string, a, b Tensor) (Tensor, error) {
+	if len(specA) != 2 || len(specB) != 2 || len(out) != 2 || specA[1] != specB[0] || out[0] != specA[0] || out[1] != specB[1] {
+		return nil, errors.Errorf("Einsum: the default engine only supports a batched-matmul ellipsis pattern (e.g. \"...ij,...jk->...ik\"), got \"...%s,...%s->...%s\"", specA, specB, out)
+	}
+	return e.BatchMatMul(a, b)
+}
+
+func (e StdEng) einsumPlain(specA, specB, out string, a, b Tensor) (Tensor, error) {
+	contracted := make(map[rune]bool)
+	for _, l := range specA {
+		if strings.ContainsRune(specB, l) && !strings.ContainsRune(out, l) {
+			contracted[l] = true
+		}
+	}
+
+	var axesA, axesB []int
+	for i, l := range specA {
+		if contracted[l] {
+			axesA = append(axesA, i)
+			axesB = append(axesB, strings.IndexRune(specB, l))
+		}
+	}
+
+	var freeALabels, freeBLabels []rune
+	for _, l := range specA {
+		if !contracted[l] {
+			freeALabels = append(freeALabels, l)
+		}
+	}
+	for _, l := range specB {
+		if !contracted[l] {
+			freeBLabels = append(freeBLabels, l)
+		}
+	}
+
+	result, err := e.Tensordot(a, b, axesA, axesB)
+	if err != nil {
+		return nil, errors.Wrap(err, "Einsum")
+	}
+
+	resultLabels := append(append([]rune{}, freeALabels...), freeBLabels...)
+	if len(resultLabels) != len(out) {
+		return nil, errors.Errorf("Einsum: output spec %q does not match the %d free axes produced by contracting %q and %q", out, len(resultLabels), specA, specB)
+	}
+
+	order := make([]int, len(out))
+	for i, l := range out {
+		idx := -1
+		for j, rl := range resultLabels {
+			if rl == l {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, errors.Errorf("Einsum: output label %q not found among the free axes of %q and %q", string(l), specA, specB)
+		}
+		order[i] = idx
+	}
+	return permuteTensor(result, order)
+}
+
+// freeAxes returns the axes in [0, dims) that do not appear in axes, in
+// increasing order.
+func freeAxes(dims int, axes []int) []int {
+	excluded := make(map[int]bool, len(axes))
+	for _, ax := range axes {
+		excluded[ax] = true
+	}
+	var free []int
+	for i := 0; i < dims; i++ {
+		if !excluded[i] {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+// permuteF64 returns a new flat row-major buffer holding data (whose real
+// shape is shape) with its axes reordered: the i-th axis of the result is
+// shape's order[i]-th axis.
+func permuteF64(data []float64, shape Shape, order []int) []float64 {
+	strides := rowMajorStrides(shape)
+	newShape := make(Shape, len(order))
+	for i, ax := range order {
+		newShape[i] = shape[ax]
+	}
+
+	out := make([]float64, len(data))
+	idx := make([]int, len(newShape))
+	for i := range out {
+		offset := 0
+		for j, ax := range order {
+			offset += idx[j] * strides[ax]
+		}
+		out[i] = data[offset]
+		incrOdometer(idx, newShape)
+	}
+	return out
+}
+
+// permuteF32 is permuteF64 for []float32 data.
+func permuteF32(data []float32, shape Shape, order []int) []float32 {
+	strides := rowMajorStrides(shape)
+	newShape := make(Shape, len(order))
+	for i, ax := range order {
+		newShape[i] = shape[ax]
+	}
+
+	out := make([]float32, len(data))
+	idx := make([]int, len(newShape))
+	for i := range out {
+		offset := 0
+		for j, ax := range order {
+			offset += idx[j] * strides[ax]
+		}
+		out[i] = data[offset]
+		incrOdometer(idx, newShape)
+	}
+	return out
+}
+
+// permuteTensor reorders t's axes according to order, dispatching on dtype.
+func permuteTensor(t Tensor, order []int) (Tensor, error) {
+	shape := t.Shape()
+	newShape := make(Shape, len(order))
+	for i, ax := range order {
+		newShape[i] = shape[ax]
+	}
+
+	switch t.Dtype() {
+	case Float64:
+		return New(Of(Float64), WithShape(newShape...), WithBacking(permuteF64(t.Data().([]float64), shape, order))), nil
+	case Float32:
+		return New(Of(Float32), WithShape(newShape...), WithBacking(permuteF32(t.Data().([]float32), shape, order))), nil
+	default:
+		return nil, errors.Errorf(nyiFail, "permuteTensor", t.Dtype())
+	}
+}
@@ -0,0 +1,85 @@
+package tensor
+
+import "testing"
+
+func TestTensordotContractsSharedAxis(t *testing.T) {
+	a := New(Of(Float64), WithShape(2, 3), WithBacking([]float64{
+		1, 2, 3,
+		4, 5, 6,
+	}))
+	b := New(Of(Float64), WithShape(3, 2), WithBacking([]float64{
+		7, 8,
+		9, 10,
+		11, 12,
+	}))
+
+	got, err := Tensordot(a, b, []int{1}, []int{0})
+	if err != nil {
+		t.Fatalf("Tensordot: %v", err)
+	}
+
+	want := []float64{58, 64, 139, 154}
+	gotData, ok := got.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", got.Data())
+	}
+	for i, w := range want {
+		if gotData[i] != w {
+			t.Errorf("at index %d: got %v, want %v", i, gotData[i], w)
+		}
+	}
+}
+
+func TestBatchMatMulBroadcastsBatchDim(t *testing.T) {
+	a := New(Of(Float64), WithShape(2, 2, 2), WithBacking([]float64{
+		1, 0, 0, 1,
+		2, 0, 0, 2,
+	}))
+	b := New(Of(Float64), WithShape(1, 2, 2), WithBacking([]float64{
+		1, 2,
+		3, 4,
+	}))
+
+	got, err := BatchMatMul(a, b)
+	if err != nil {
+		t.Fatalf("BatchMatMul: %v", err)
+	}
+
+	want := []float64{1, 2, 3, 4, 2, 4, 6, 8}
+	gotData, ok := got.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", got.Data())
+	}
+	for i, w := range want {
+		if gotData[i] != w {
+			t.Errorf("at index %d: got %v, want %v", i, gotData[i], w)
+		}
+	}
+}
+
+func TestEinsumBatchedMatMul(t *testing.T) {
+	a := New(Of(Float64), WithShape(2, 2, 2), WithBacking([]float64{
+		1, 0, 0, 1,
+		2, 0, 0, 2,
+	}))
+	b := New(Of(Float64), WithShape(2, 2, 2), WithBacking([]float64{
+		1, 2, 3, 4,
+		1, 2, 3, 4,
+	}))
+
+	got, err := Einsum("...ij,...jk->...ik", a, b)
+	if err != nil {
+		t.Fatalf("Einsum: %v", err)
+	}
+
+	want := []float64{1, 2, 3, 4, 2, 4, 6, 8}
+	gotData, ok := got.Data().([]float64)
+	if !ok {
+		t.Fatalf("expected []float64 data, got %T", got.Data())
+	}
+	for i, w := range want {
+		if gotData[i] != w {
+			t.Errorf("at index %d: got %v, want %v", i, gotData[i], w)
+		}
+	}
+}